@@ -46,6 +46,7 @@ func (src *Cluster) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Status.ControlPlaneReady = restored.Status.ControlPlaneReady
 	dst.Status.FailureDomains = restored.Status.FailureDomains
 	dst.Spec.Paused = restored.Spec.Paused
+	dst.Status.Conditions = restored.Status.Conditions
 
 	return nil
 }
@@ -110,6 +111,10 @@ func (src *Machine) ConvertTo(dstRaw conversion.Hub) error {
 		return err
 	}
 	restoreMachineSpec(&restored.Spec, &dst.Spec)
+	dst.Status.BootstrapReadyTime = restored.Status.BootstrapReadyTime
+	dst.Status.InfrastructureReadyTime = restored.Status.InfrastructureReadyTime
+	dst.Status.NodeReadyTime = restored.Status.NodeReadyTime
+	dst.Status.Conditions = restored.Status.Conditions
 
 	return nil
 }
@@ -120,6 +125,7 @@ func restoreMachineSpec(restored *v1alpha3.MachineSpec, dst *v1alpha3.MachineSpe
 	}
 	dst.Bootstrap.DataSecretName = restored.Bootstrap.DataSecretName
 	dst.FailureDomain = restored.FailureDomain
+	dst.NodeReadinessGracePeriod = restored.NodeReadinessGracePeriod
 }
 
 func (dst *Machine) ConvertFrom(srcRaw conversion.Hub) error {
@@ -176,6 +182,7 @@ func (src *MachineSet) ConvertTo(dstRaw conversion.Hub) error {
 	if restored.Spec.ClusterName != "" {
 		dst.Spec.ClusterName = restored.Spec.ClusterName
 	}
+	dst.Status.Conditions = restored.Status.Conditions
 	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
 
 	return nil
@@ -231,6 +238,10 @@ func (src *MachineDeployment) ConvertTo(dstRaw conversion.Hub) error {
 	}
 	dst.Spec.Paused = restored.Spec.Paused
 	dst.Status.Phase = restored.Status.Phase
+	dst.Status.Conditions = restored.Status.Conditions
+	if restored.Spec.Strategy != nil && dst.Spec.Strategy != nil {
+		dst.Spec.Strategy.MinHealthyReplicas = restored.Spec.Strategy.MinHealthyReplicas
+	}
 	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
 
 	return nil
@@ -367,6 +378,10 @@ func Convert_v1alpha3_MachineDeploymentStatus_To_v1alpha2_MachineDeploymentStatu
 	return autoConvert_v1alpha3_MachineDeploymentStatus_To_v1alpha2_MachineDeploymentStatus(in, out, s)
 }
 
+func Convert_v1alpha3_MachineDeploymentStrategy_To_v1alpha2_MachineDeploymentStrategy(in *v1alpha3.MachineDeploymentStrategy, out *MachineDeploymentStrategy, s apiconversion.Scope) error {
+	return autoConvert_v1alpha3_MachineDeploymentStrategy_To_v1alpha2_MachineDeploymentStrategy(in, out, s)
+}
+
 func Convert_v1alpha3_MachineSetSpec_To_v1alpha2_MachineSetSpec(in *v1alpha3.MachineSetSpec, out *MachineSetSpec, s apiconversion.Scope) error {
 	return autoConvert_v1alpha3_MachineSetSpec_To_v1alpha2_MachineSetSpec(in, out, s)
 }
@@ -20,6 +20,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -48,12 +49,12 @@ func (c *Cluster) Default() {
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (c *Cluster) ValidateCreate() error {
-	return c.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("Cluster"), capiwebhook.OperationCreate, c, c.validate)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (c *Cluster) ValidateUpdate(old runtime.Object) error {
-	return c.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("Cluster"), capiwebhook.OperationUpdate, c, c.validate)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
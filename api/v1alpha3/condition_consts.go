@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+// ANCHOR: CommonConditions
+
+// Common ConditionTypes used by Cluster API objects.
+const (
+	// ReadyCondition defines the Ready condition type that summarizes the operational state of a Cluster API object.
+	ReadyCondition ConditionType = "Ready"
+)
+
+// ANCHOR_END: CommonConditions
+
+// Conditions and condition Reasons shared by the Cluster and Machine objects
+
+const (
+	// ProviderCRDInstalledCondition documents that the CRDs of the infrastructure and bootstrap objects
+	// referenced by a Cluster API object are installed in the management cluster.
+	ProviderCRDInstalledCondition ConditionType = "ProviderCRDInstalled"
+
+	// ProviderCRDNotInstalledReason (Severity=Warning) documents a referenced object whose kind isn't known
+	// to the API server yet, e.g. because the provider's CRDs haven't been applied.
+	ProviderCRDNotInstalledReason = "ProviderCRDNotInstalled"
+)
+
+// Conditions and condition Reasons for the Cluster object
+
+const (
+	// KubeconfigReadyCondition documents that the Kubeconfig secret of a Cluster stores a kubeconfig
+	// that can be used to create clients for the workload cluster.
+	KubeconfigReadyCondition ConditionType = "KubeconfigReady"
+
+	// InvalidKubeconfigSecretReason (Severity=Error) documents a Kubeconfig secret that doesn't store
+	// the kubeconfig under the expected data key, or stores data that can't be parsed as a kubeconfig.
+	InvalidKubeconfigSecretReason = "InvalidKubeconfigSecret"
+)
+
+// Conditions and condition Reasons for the Machine object
+
+const (
+	// BootstrapReadyCondition reports a summary of current status of the bootstrap object defined for this machine.
+	BootstrapReadyCondition ConditionType = "BootstrapReady"
+
+	// WaitingForBootstrapDataReason (Severity=Info) documents a machine waiting for the bootstrap
+	// data to be ready before starting to create the infrastructure.
+	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
+
+	// InfrastructureReadyCondition reports a summary of current status of the infrastructure object defined for this machine.
+	InfrastructureReadyCondition ConditionType = "InfrastructureReady"
+
+	// WaitingForInfrastructureReason (Severity=Info) documents a machine waiting for the infrastructure
+	// provider to report the infrastructure as ready.
+	WaitingForInfrastructureReason = "WaitingForInfrastructure"
+
+	// KubeletVersionMatchesCondition documents that the kubelet running on the Node backing this machine
+	// reports the version defined in the machine spec.
+	KubeletVersionMatchesCondition ConditionType = "KubeletVersionMatches"
+
+	// KubeletVersionMismatchReason (Severity=Warning) documents a Node reporting a kubelet version
+	// different from the one defined in the machine spec, e.g. after a failed upgrade or when booting from a stale image.
+	KubeletVersionMismatchReason = "KubeletVersionMismatch"
+
+	// NodeRefConsistentCondition documents that the Node referenced by this machine reports the
+	// ProviderID defined in the machine spec.
+	NodeRefConsistentCondition ConditionType = "NodeRefConsistent"
+
+	// NodeRefStaleReason (Severity=Warning) documents a machine whose NodeRef pointed to a Node reporting another
+	// ProviderID, e.g. after the Node was recreated on a reused instance. The NodeRef is cleared and resolved again.
+	NodeRefStaleReason = "NodeRefStale"
+
+	// NodeReadyCondition documents that the Node backing this machine has been Ready continuously
+	// for spec.nodeReadinessGracePeriod. It's only set on machines defining a grace period.
+	NodeReadyCondition ConditionType = "NodeReady"
+
+	// NodeNotReadyReason (Severity=Warning) documents a machine whose Node isn't Ready.
+	NodeNotReadyReason = "NodeNotReady"
+
+	// WaitingForNodeReadinessGracePeriodReason (Severity=Info) documents a machine whose Node is Ready,
+	// but hasn't been Ready for spec.nodeReadinessGracePeriod yet.
+	WaitingForNodeReadinessGracePeriodReason = "WaitingForNodeReadinessGracePeriod"
+)
+
+// Conditions and condition Reasons shared by the MachineSet and MachineDeployment objects
+
+const (
+	// AnnotationsPropagatedCondition documents that the annotations of a MachineDeployment, or of the template of
+	// a MachineSet, are all copied to the objects created from it. It's only set once annotations were skipped.
+	AnnotationsPropagatedCondition ConditionType = "AnnotationsPropagated"
+
+	// AnnotationsSkippedReason (Severity=Warning) documents annotations that aren't copied because they would
+	// grow the annotations of the target object over the size limit enforced by the API server.
+	AnnotationsSkippedReason = "AnnotationsSkipped"
+)
+
+// Conditions and condition Reasons for the MachineSet object
+
+const (
+	// MachinesCreatedCondition documents that the machines controlled by the MachineSet are created.
+	// When this condition is false, it indicates that there was an error when cloning the infrastructure/bootstrap template or
+	// when generating the machine object.
+	MachinesCreatedCondition ConditionType = "MachinesCreated"
+
+	// TemplateNotFoundReason (Severity=Error) documents that the infrastructure or bootstrap template
+	// referenced by the MachineSet can't be found.
+	TemplateNotFoundReason = "TemplateNotFound"
+
+	// TemplateCloningFailedReason (Severity=Error) documents a MachineSet failing to
+	// clone the infrastructure or bootstrap template.
+	TemplateCloningFailedReason = "TemplateCloningFailed"
+
+	// MachineCreationFailedReason (Severity=Error) documents a MachineSet failing to
+	// generate a machine object.
+	MachineCreationFailedReason = "MachineCreationFailed"
+)
+
+// Conditions and condition Reasons for the MachinePool object
+
+const (
+	// InstancesDeletedCondition documents that the Machines and the infrastructure instances of a MachinePool
+	// being deleted are gone, so its finalizer can be removed.
+	InstancesDeletedCondition ConditionType = "InstancesDeleted"
+
+	// DeletingReason (Severity=Info) documents a MachinePool waiting for its Machines and infrastructure
+	// instances to be deleted.
+	DeletingReason = "Deleting"
+)
+
+// Conditions and condition Reasons for the MachineDeployment object
+
+const (
+	// MachineSetsReadyCondition reports the most severe blocking condition of the MachineSets
+	// owned by a MachineDeployment, so a stuck rollout can be diagnosed from the MachineDeployment alone.
+	// The condition's Reason is the Reason of the mirrored MachineSet condition, while the Message
+	// names the MachineSet it originates from.
+	MachineSetsReadyCondition ConditionType = "MachineSetsReady"
+
+	// MinHealthyReplicasSatisfiedCondition documents that a MachineDeployment can replace or scale down
+	// machines without bringing the number of ready machines below spec.strategy.minHealthyReplicas.
+	MinHealthyReplicasSatisfiedCondition ConditionType = "MinHealthyReplicasSatisfied"
+
+	// ScaleDownDeferredReason (Severity=Warning) documents a MachineDeployment deferring the scale down
+	// of its MachineSets until enough machines are ready to keep spec.strategy.minHealthyReplicas.
+	ScaleDownDeferredReason = "ScaleDownDeferred"
+)
@@ -37,6 +37,15 @@ const (
 
 	// MachineDeploymentLabelName is the label set on machines if they're controlled by MachineDeployment
 	MachineDeploymentLabelName = "cluster.x-k8s.io/deployment-name"
+
+	// MachineRegionLabelName is the label set on machines with the region reported in the status of their infrastructure object.
+	MachineRegionLabelName = "topology.cluster.x-k8s.io/region"
+
+	// MachineZoneLabelName is the label set on machines with the zone reported in the status of their infrastructure object.
+	MachineZoneLabelName = "topology.cluster.x-k8s.io/zone"
+
+	// MachineInstanceTypeLabelName is the label set on machines with the instance type reported in the status of their infrastructure object.
+	MachineInstanceTypeLabelName = "cluster.x-k8s.io/instance-type"
 )
 
 // ANCHOR: MachineSpec
@@ -77,6 +86,13 @@ type MachineSpec struct {
 	// Must match a key in the FailureDomains map stored on the cluster object.
 	// +optional
 	FailureDomain *string `json:"failureDomain,omitempty"`
+
+	// NodeReadinessGracePeriod is the amount of time the Node backing this machine must have been Ready
+	// continuously before the machine is considered ready, e.g. to filter out Nodes flapping right after join.
+	// Defaults to 0, meaning the machine is ready as soon as its Node is.
+	// Not supported in MachinePool templates.
+	// +optional
+	NodeReadinessGracePeriod *metav1.Duration `json:"nodeReadinessGracePeriod,omitempty"`
 }
 
 // ANCHOR_END: MachineSpec
@@ -155,6 +171,23 @@ type MachineStatus struct {
 	// InfrastructureReady is the state of the infrastructure provider.
 	// +optional
 	InfrastructureReady bool `json:"infrastructureReady"`
+
+	// BootstrapReadyTime is the time when BootstrapReady first became true.
+	// +optional
+	BootstrapReadyTime *metav1.Time `json:"bootstrapReadyTime,omitempty"`
+
+	// InfrastructureReadyTime is the time when InfrastructureReady first became true.
+	// +optional
+	InfrastructureReadyTime *metav1.Time `json:"infrastructureReadyTime,omitempty"`
+
+	// NodeReadyTime is the time the Node backing this machine last became Ready.
+	// It's cleared while the Node isn't Ready.
+	// +optional
+	NodeReadyTime *metav1.Time `json:"nodeReadyTime,omitempty"`
+
+	// Conditions defines current service state of the Machine.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // ANCHOR_END: MachineStatus
@@ -227,6 +260,16 @@ type Machine struct {
 	Status MachineStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (in *Machine) GetConditions() Conditions {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (in *Machine) SetConditions(conditions Conditions) {
+	in.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineList contains a list of Machine
@@ -17,14 +17,26 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 func (m *Machine) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	referenceReader = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(m).
 		Complete()
@@ -49,12 +61,20 @@ func (m *Machine) Default() {
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (m *Machine) ValidateCreate() error {
-	return m.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("Machine"), capiwebhook.OperationCreate, m, func() error {
+		return m.validate(nil)
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (m *Machine) ValidateUpdate(old runtime.Object) error {
-	return m.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("Machine"), capiwebhook.OperationUpdate, m, func() error {
+		oldM, ok := old.(*Machine)
+		if !ok {
+			return apierrors.NewBadRequest(fmt.Sprintf("expected a Machine but got a %T", old))
+		}
+		return m.validate(oldM)
+	})
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -62,7 +82,7 @@ func (m *Machine) ValidateDelete() error {
 	return nil
 }
 
-func (m *Machine) validate() error {
+func (m *Machine) validate(old *Machine) error {
 	var allErrs field.ErrorList
 	if m.Spec.Bootstrap.ConfigRef == nil && m.Spec.Bootstrap.DataSecretName == nil {
 		allErrs = append(
@@ -96,8 +116,106 @@ func (m *Machine) validate() error {
 		)
 	}
 
+	var oldSpec *MachineSpec
+	if old != nil {
+		oldSpec = &old.Spec
+	}
+	allErrs = append(allErrs, validateReferencesNotSwapped(&m.Spec, oldSpec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateNodeReadinessGracePeriod(&m.Spec, field.NewPath("spec"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Machine").GroupKind(), m.Name, allErrs)
 }
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+
+// referenceReader reads the CRDs of the objects referenced by Machines, MachineSets and MachineDeployments.
+// It is set when their webhooks are set up with a manager.
+var referenceReader client.Reader
+
+const (
+	bootstrapProvider      = "bootstrap"
+	infrastructureProvider = "infrastructure"
+)
+
+// validateReferencesNotSwapped rejects a MachineSpec whose bootstrap.configRef references an infrastructure
+// provider object, or whose infrastructureRef references a bootstrap provider object. On update, only the
+// references changed from oldSpec are checked, so objects admitted before this validation existed can still
+// be updated.
+func validateReferencesNotSwapped(spec, oldSpec *MachineSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if ref := spec.Bootstrap.ConfigRef; ref != nil && (oldSpec == nil || !reflect.DeepEqual(ref, oldSpec.Bootstrap.ConfigRef)) &&
+		referenceProvider(ref) == infrastructureProvider {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				path.Child("bootstrap", "configRef", "apiVersion"),
+				ref.APIVersion,
+				fmt.Sprintf("must reference a bootstrap provider object, but %s %q is an infrastructure provider object; bootstrap.configRef and infrastructureRef may have been swapped", ref.Kind, ref.Name),
+			),
+		)
+	}
+
+	if ref := &spec.InfrastructureRef; (oldSpec == nil || !reflect.DeepEqual(*ref, oldSpec.InfrastructureRef)) &&
+		referenceProvider(ref) == bootstrapProvider {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				path.Child("infrastructureRef", "apiVersion"),
+				ref.APIVersion,
+				fmt.Sprintf("must reference an infrastructure provider object, but %s %q is a bootstrap provider object; bootstrap.configRef and infrastructureRef may have been swapped", ref.Kind, ref.Name),
+			),
+		)
+	}
+
+	return allErrs
+}
+
+// referenceProvider returns the type of provider, bootstrap or infrastructure, owning the kind of the object
+// referenced by ref. It's read from the provider label of the kind's CRD, e.g. infrastructure-aws. When the CRD
+// can't be read or isn't labeled, it's derived from the API group of the object instead, e.g.
+// infrastructure.cluster.x-k8s.io. It returns an empty string if the type of provider is unknown.
+func referenceProvider(ref *corev1.ObjectReference) string {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return ""
+	}
+
+	if referenceReader != nil {
+		crd := &unstructured.Unstructured{}
+		crd.SetAPIVersion("apiextensions.k8s.io/v1")
+		crd.SetKind("CustomResourceDefinition")
+		resource, _ := meta.UnsafeGuessKindToResource(gv.WithKind(ref.Kind))
+		key := client.ObjectKey{Name: fmt.Sprintf("%s.%s", resource.Resource, gv.Group)}
+		if err := referenceReader.Get(context.TODO(), key, crd); err == nil {
+			provider := crd.GetLabels()[ProviderLabelName]
+			switch {
+			case strings.HasPrefix(provider, bootstrapProvider+"-"):
+				return bootstrapProvider
+			case strings.HasPrefix(provider, infrastructureProvider+"-"):
+				return infrastructureProvider
+			}
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(gv.Group, bootstrapProvider+"."):
+		return bootstrapProvider
+	case strings.HasPrefix(gv.Group, infrastructureProvider+"."):
+		return infrastructureProvider
+	}
+	return ""
+}
+
+// validateNodeReadinessGracePeriod rejects a MachineSpec with a negative nodeReadinessGracePeriod.
+func validateNodeReadinessGracePeriod(spec *MachineSpec, path *field.Path) field.ErrorList {
+	if spec.NodeReadinessGracePeriod == nil || spec.NodeReadinessGracePeriod.Duration >= 0 {
+		return nil
+	}
+	return field.ErrorList{
+		field.Invalid(path.Child("nodeReadinessGracePeriod"), spec.NodeReadinessGracePeriod.Duration.String(), "must be greater than or equal to 0"),
+	}
+}
@@ -18,11 +18,14 @@ package v1alpha3
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestMachineDefault(t *testing.T) {
@@ -74,10 +77,10 @@ func TestMachineBootstrapValidation(t *testing.T) {
 			}
 			if tt.expectErr {
 				g.Expect(m.ValidateCreate()).NotTo(Succeed())
-				g.Expect(m.ValidateUpdate(nil)).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
 			} else {
 				g.Expect(m.ValidateCreate()).To(Succeed())
-				g.Expect(m.ValidateUpdate(nil)).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
 			}
 		})
 	}
@@ -132,10 +135,197 @@ func TestMachineNamespaceValidation(t *testing.T) {
 
 			if tt.expectErr {
 				g.Expect(m.ValidateCreate()).NotTo(Succeed())
-				g.Expect(m.ValidateUpdate(nil)).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
 			} else {
 				g.Expect(m.ValidateCreate()).To(Succeed())
-				g.Expect(m.ValidateUpdate(nil)).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineReferencesSwappedValidation(t *testing.T) {
+	bootstrapRef := corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3", Kind: "KubeadmConfig", Name: "foo"}
+	infraRef := corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3", Kind: "AWSMachine", Name: "foo"}
+
+	tests := []struct {
+		name      string
+		bootstrap Bootstrap
+		infraRef  corev1.ObjectReference
+		expectErr bool
+	}{
+		{
+			name:      "should succeed if references point to the expected providers",
+			bootstrap: Bootstrap{ConfigRef: bootstrapRef.DeepCopy()},
+			infraRef:  infraRef,
+			expectErr: false,
+		},
+		{
+			name:      "should return error if references are swapped",
+			bootstrap: Bootstrap{ConfigRef: infraRef.DeepCopy()},
+			infraRef:  bootstrapRef,
+			expectErr: true,
+		},
+		{
+			name:      "should return error if config ref points to an infrastructure provider",
+			bootstrap: Bootstrap{ConfigRef: infraRef.DeepCopy()},
+			infraRef:  infraRef,
+			expectErr: true,
+		},
+		{
+			name:      "should return error if infrastructure ref points to a bootstrap provider",
+			bootstrap: Bootstrap{DataSecretName: pointer.StringPtr("test")},
+			infraRef:  bootstrapRef,
+			expectErr: true,
+		},
+		{
+			name:      "should succeed if references point to unknown API groups",
+			bootstrap: Bootstrap{ConfigRef: &corev1.ObjectReference{APIVersion: "example.com/v1", Kind: "Config"}},
+			infraRef:  corev1.ObjectReference{APIVersion: "example.com/v1", Kind: "Server"},
+			expectErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				Spec: MachineSpec{Bootstrap: tt.bootstrap, InfrastructureRef: tt.infraRef},
+			}
+			old := &Machine{
+				Spec: MachineSpec{
+					Bootstrap:         Bootstrap{ConfigRef: &corev1.ObjectReference{APIVersion: "example.com/v1", Kind: "OtherConfig"}},
+					InfrastructureRef: corev1.ObjectReference{APIVersion: "example.com/v1", Kind: "OtherServer"},
+				},
+			}
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(old)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(old)).To(Succeed())
+			}
+			// References that aren't changed by an update are not checked again.
+			g.Expect(m.ValidateUpdate(m.DeepCopy())).To(Succeed())
+		})
+	}
+}
+
+func TestMachineReferencesSwappedValidationWithProviderLabels(t *testing.T) {
+	newCRD := func(name, provider string) *unstructured.Unstructured {
+		crd := &unstructured.Unstructured{}
+		crd.SetAPIVersion("apiextensions.k8s.io/v1")
+		crd.SetKind("CustomResourceDefinition")
+		crd.SetName(name)
+		crd.SetLabels(map[string]string{ProviderLabelName: provider})
+		return crd
+	}
+	referenceReader = fake.NewFakeClient(
+		newCRD("servers.example.com", "infrastructure-example"),
+		newCRD("configs.example.com", "bootstrap-example"),
+		newCRD("kubeadmconfigs.bootstrap.cluster.x-k8s.io", "bootstrap-kubeadm"),
+	)
+	defer func() { referenceReader = nil }()
+
+	configRef := corev1.ObjectReference{APIVersion: "example.com/v1", Kind: "Config", Name: "foo"}
+	serverRef := corev1.ObjectReference{APIVersion: "example.com/v1", Kind: "Server", Name: "foo"}
+	kubeadmConfigRef := corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3", Kind: "KubeadmConfig", Name: "foo"}
+	// The CRD of AWSMachine isn't installed, so its provider is derived from its API group.
+	awsMachineRef := corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3", Kind: "AWSMachine", Name: "foo"}
+
+	tests := []struct {
+		name      string
+		configRef corev1.ObjectReference
+		infraRef  corev1.ObjectReference
+		expectErr bool
+	}{
+		{
+			name:      "should succeed if the CRDs are labeled with the expected providers",
+			configRef: configRef,
+			infraRef:  serverRef,
+			expectErr: false,
+		},
+		{
+			name:      "should return error if the CRDs are labeled with swapped providers",
+			configRef: serverRef,
+			infraRef:  configRef,
+			expectErr: true,
+		},
+		{
+			name:      "should return error if infrastructure ref points to a labeled bootstrap provider",
+			configRef: kubeadmConfigRef,
+			infraRef:  configRef,
+			expectErr: true,
+		},
+		{
+			name:      "should fall back to the API group if a CRD can't be read",
+			configRef: awsMachineRef,
+			infraRef:  serverRef,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				Spec: MachineSpec{Bootstrap: Bootstrap{ConfigRef: tt.configRef.DeepCopy()}, InfrastructureRef: tt.infraRef},
+			}
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineNodeReadinessGracePeriodValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		gracePeriod *metav1.Duration
+		expectErr   bool
+	}{
+		{
+			name:        "should succeed if the grace period is not set",
+			gracePeriod: nil,
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed if the grace period is positive",
+			gracePeriod: &metav1.Duration{Duration: 2 * time.Minute},
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed if the grace period is 0",
+			gracePeriod: &metav1.Duration{},
+			expectErr:   false,
+		},
+		{
+			name:        "should return error if the grace period is negative",
+			gracePeriod: &metav1.Duration{Duration: -time.Second},
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				Spec: MachineSpec{
+					Bootstrap:                Bootstrap{DataSecretName: pointer.StringPtr("test")},
+					NodeReadinessGracePeriod: tt.gracePeriod,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
 			}
 		})
 	}
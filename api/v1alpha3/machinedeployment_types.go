@@ -29,6 +29,17 @@ const (
 	RollingUpdateMachineDeploymentStrategyType MachineDeploymentStrategyType = "RollingUpdate"
 )
 
+const (
+	// ReconcileIntervalAnnotation is the annotation set on a MachineDeployment to override how often
+	// it's reconciled while a rollout or scaling operation is in progress.
+	// The value must be a duration string, e.g. "10s" or "1m".
+	ReconcileIntervalAnnotation = "cluster.x-k8s.io/reconcile-interval"
+
+	// AllowDowngradeAnnotation is the annotation set on a MachineDeployment to allow lowering
+	// spec.template.spec.version below its current value.
+	AllowDowngradeAnnotation = "cluster.x-k8s.io/allow-downgrade"
+)
+
 // ANCHOR: MachineDeploymentSpec
 
 // MachineDeploymentSpec defines the desired state of MachineDeployment
@@ -96,6 +107,15 @@ type MachineDeploymentStrategy struct {
 	// MachineDeploymentStrategyType = RollingUpdate.
 	// +optional
 	RollingUpdate *MachineRollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+
+	// MinHealthyReplicas is the minimum number of ready machines the
+	// MachineDeployment must keep. Machines are not scaled down or replaced
+	// when doing so would bring the number of ready machines below this value;
+	// the operation is deferred until enough machines are ready again.
+	// Defaults to no floor.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinHealthyReplicas *int32 `json:"minHealthyReplicas,omitempty"`
 }
 
 // ANCHOR_END: MachineDeploymentStrategy
@@ -182,6 +202,10 @@ type MachineDeploymentStatus struct {
 	// Phase represents the current phase of a MachineDeployment (ScalingUp, ScalingDown, Running, Failed, or Unknown).
 	// +optional
 	Phase string `json:"phase,omitempty"`
+
+	// Conditions defines current service state of the MachineDeployment.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // ANCHOR_END: MachineDeploymentStatus
@@ -245,6 +269,16 @@ type MachineDeployment struct {
 	Status MachineDeploymentStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (in *MachineDeployment) GetConditions() Conditions {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (in *MachineDeployment) SetConditions(conditions Conditions) {
+	in.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineDeploymentList contains a list of MachineDeployment
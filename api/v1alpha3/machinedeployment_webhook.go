@@ -25,12 +25,15 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/utils/pointer"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 func (m *MachineDeployment) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	referenceReader = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(m).
 		Complete()
@@ -49,12 +52,20 @@ func (m *MachineDeployment) Default() {
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachineDeployment) ValidateCreate() error {
-	return m.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("MachineDeployment"), capiwebhook.OperationCreate, m, func() error {
+		return m.validate(nil)
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachineDeployment) ValidateUpdate(old runtime.Object) error {
-	return m.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("MachineDeployment"), capiwebhook.OperationUpdate, m, func() error {
+		oldMD, ok := old.(*MachineDeployment)
+		if !ok {
+			return apierrors.NewBadRequest(fmt.Sprintf("expected a MachineDeployment but got a %T", old))
+		}
+		return m.validate(oldMD)
+	})
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -62,7 +73,7 @@ func (m *MachineDeployment) ValidateDelete() error {
 	return nil
 }
 
-func (m *MachineDeployment) validate() error {
+func (m *MachineDeployment) validate(old *MachineDeployment) error {
 	var allErrs field.ErrorList
 	selector, err := metav1.LabelSelectorAsSelector(&m.Spec.Selector)
 	if err != nil {
@@ -81,6 +92,17 @@ func (m *MachineDeployment) validate() error {
 		)
 	}
 
+	var oldSpec *MachineSpec
+	if old != nil {
+		oldSpec = &old.Spec.Template.Spec
+	}
+	allErrs = append(allErrs, validateReferencesNotSwapped(&m.Spec.Template.Spec, oldSpec, field.NewPath("spec", "template", "spec"))...)
+	allErrs = append(allErrs, validateNodeReadinessGracePeriod(&m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+
+	if old != nil {
+		allErrs = append(allErrs, m.validateVersionDowngrade(old)...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -88,6 +110,37 @@ func (m *MachineDeployment) validate() error {
 	return apierrors.NewInvalid(GroupVersion.WithKind("MachineDeployment").GroupKind(), m.Name, allErrs)
 }
 
+// validateVersionDowngrade rejects lowering spec.template.spec.version, unless the MachineDeployment
+// has the AllowDowngradeAnnotation. Versions that can't be parsed are not compared.
+func (m *MachineDeployment) validateVersionDowngrade(old *MachineDeployment) field.ErrorList {
+	if _, ok := m.Annotations[AllowDowngradeAnnotation]; ok {
+		return nil
+	}
+	if old.Spec.Template.Spec.Version == nil || m.Spec.Template.Spec.Version == nil {
+		return nil
+	}
+
+	oldVersion, err := version.ParseSemantic(*old.Spec.Template.Spec.Version)
+	if err != nil {
+		return nil
+	}
+	newVersion, err := version.ParseSemantic(*m.Spec.Template.Spec.Version)
+	if err != nil {
+		return nil
+	}
+
+	if newVersion.LessThan(oldVersion) {
+		return field.ErrorList{
+			field.Forbidden(
+				field.NewPath("spec", "template", "spec", "version"),
+				fmt.Sprintf("cannot downgrade from %q to %q, set the %q annotation to allow it",
+					*old.Spec.Template.Spec.Version, *m.Spec.Template.Spec.Version, AllowDowngradeAnnotation),
+			),
+		}
+	}
+	return nil
+}
+
 // PopulateDefaultsMachineDeployment fills in default field values.
 // This is also called during MachineDeployment sync.
 func PopulateDefaultsMachineDeployment(d *MachineDeployment) {
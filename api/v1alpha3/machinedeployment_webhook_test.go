@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
@@ -103,11 +104,120 @@ func TestMachineDeploymentValidation(t *testing.T) {
 			}
 			if tt.expectErr {
 				g.Expect(md.ValidateCreate()).NotTo(Succeed())
-				g.Expect(md.ValidateUpdate(nil)).NotTo(Succeed())
+				g.Expect(md.ValidateUpdate(md)).NotTo(Succeed())
 			} else {
 				g.Expect(md.ValidateCreate()).To(Succeed())
-				g.Expect(md.ValidateUpdate(nil)).To(Succeed())
+				g.Expect(md.ValidateUpdate(md)).To(Succeed())
 			}
 		})
 	}
 }
+
+func TestMachineDeploymentVersionDowngradeValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldVersion  *string
+		newVersion  *string
+		annotations map[string]string
+		expectErr   bool
+	}{
+		{
+			name:       "should succeed when upgrading",
+			oldVersion: pointer.StringPtr("v1.16.3"),
+			newVersion: pointer.StringPtr("v1.17.2"),
+			expectErr:  false,
+		},
+		{
+			name:       "should succeed when the version doesn't change",
+			oldVersion: pointer.StringPtr("v1.17.2"),
+			newVersion: pointer.StringPtr("v1.17.2"),
+			expectErr:  false,
+		},
+		{
+			name:       "should return error when downgrading",
+			oldVersion: pointer.StringPtr("v1.17.2"),
+			newVersion: pointer.StringPtr("v1.16.3"),
+			expectErr:  true,
+		},
+		{
+			name:       "should compare versions semantically",
+			oldVersion: pointer.StringPtr("v1.10.0"),
+			newVersion: pointer.StringPtr("v1.9.0"),
+			expectErr:  true,
+		},
+		{
+			name:        "should succeed when downgrading with the allow-downgrade annotation",
+			oldVersion:  pointer.StringPtr("v1.17.2"),
+			newVersion:  pointer.StringPtr("v1.16.3"),
+			annotations: map[string]string{AllowDowngradeAnnotation: ""},
+			expectErr:   false,
+		},
+		{
+			name:       "should succeed when the version is set for the first time",
+			newVersion: pointer.StringPtr("v1.16.3"),
+			expectErr:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newMD := func(version *string) *MachineDeployment {
+				return &MachineDeployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: tt.annotations,
+					},
+					Spec: MachineDeploymentSpec{
+						Selector: metav1.LabelSelector{
+							MatchLabels: map[string]string{"foo": "bar"},
+						},
+						Template: MachineTemplateSpec{
+							ObjectMeta: ObjectMeta{
+								Labels: map[string]string{"foo": "bar"},
+							},
+							Spec: MachineSpec{
+								Version: version,
+							},
+						},
+					},
+				}
+			}
+			oldMD := newMD(tt.oldVersion)
+			md := newMD(tt.newVersion)
+
+			if tt.expectErr {
+				g.Expect(md.ValidateUpdate(oldMD)).NotTo(Succeed())
+			} else {
+				g.Expect(md.ValidateUpdate(oldMD)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineDeploymentReferencesSwappedValidation(t *testing.T) {
+	g := NewWithT(t)
+
+	md := &MachineDeployment{
+		Spec: MachineDeploymentSpec{
+			Template: MachineTemplateSpec{
+				Spec: MachineSpec{
+					Bootstrap: Bootstrap{
+						ConfigRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3", Kind: "AWSMachineTemplate"},
+					},
+					InfrastructureRef: corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3", Kind: "KubeadmConfigTemplate"},
+				},
+			},
+		},
+	}
+	g.Expect(md.ValidateCreate()).NotTo(Succeed())
+	// References that aren't changed by an update are not checked again.
+	g.Expect(md.ValidateUpdate(md.DeepCopy())).To(Succeed())
+
+	old := md.DeepCopy()
+	md.Spec.Template.Spec.Bootstrap.ConfigRef, md.Spec.Template.Spec.InfrastructureRef =
+		md.Spec.Template.Spec.InfrastructureRef.DeepCopy(), *md.Spec.Template.Spec.Bootstrap.ConfigRef
+	g.Expect(md.ValidateCreate()).To(Succeed())
+	g.Expect(md.ValidateUpdate(old)).To(Succeed())
+	g.Expect(old.ValidateUpdate(md)).NotTo(Succeed())
+}
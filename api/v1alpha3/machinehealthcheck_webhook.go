@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -50,16 +51,20 @@ func (m *MachineHealthCheck) Default() {
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachineHealthCheck) ValidateCreate() error {
-	return m.validate(nil)
+	return capiwebhook.Validate(GroupVersion.WithKind("MachineHealthCheck"), capiwebhook.OperationCreate, m, func() error {
+		return m.validate(nil)
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachineHealthCheck) ValidateUpdate(old runtime.Object) error {
-	mhc, ok := old.(*MachineHealthCheck)
-	if !ok {
-		return apierrors.NewBadRequest(fmt.Sprintf("expected a MachineHealthCheck but got a %T", old))
-	}
-	return m.validate(mhc)
+	return capiwebhook.Validate(GroupVersion.WithKind("MachineHealthCheck"), capiwebhook.OperationUpdate, m, func() error {
+		mhc, ok := old.(*MachineHealthCheck)
+		if !ok {
+			return apierrors.NewBadRequest(fmt.Sprintf("expected a MachineHealthCheck but got a %T", old))
+		}
+		return m.validate(mhc)
+	})
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
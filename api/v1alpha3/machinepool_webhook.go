@@ -17,7 +17,13 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -41,14 +47,20 @@ func (m *MachinePool) Default() {
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachinePool) ValidateCreate() error {
-	// TODO(juan-lee): Add machine pool implementation.
-	return nil
+	return capiwebhook.Validate(GroupVersion.WithKind("MachinePool"), capiwebhook.OperationCreate, m, func() error {
+		return m.validate(nil)
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachinePool) ValidateUpdate(old runtime.Object) error {
-	// TODO(juan-lee): Add machine pool implementation.
-	return nil
+	return capiwebhook.Validate(GroupVersion.WithKind("MachinePool"), capiwebhook.OperationUpdate, m, func() error {
+		oldMP, ok := old.(*MachinePool)
+		if !ok {
+			return apierrors.NewBadRequest(fmt.Sprintf("expected a MachinePool but got a %T", old))
+		}
+		return m.validate(oldMP)
+	})
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -56,3 +68,26 @@ func (m *MachinePool) ValidateDelete() error {
 	// TODO(juan-lee): Add machine pool implementation.
 	return nil
 }
+
+func (m *MachinePool) validate(old *MachinePool) error {
+	// TODO(juan-lee): Add machine pool implementation.
+	var allErrs field.ErrorList
+
+	// The instances of a MachinePool have no Machines to hold back until their Node has been Ready long enough.
+	// Values set before this was validated are left alone, so those MachinePools can still be updated.
+	gracePeriod := m.Spec.Template.Spec.NodeReadinessGracePeriod
+	if gracePeriod != nil && (old == nil || !reflect.DeepEqual(gracePeriod, old.Spec.Template.Spec.NodeReadinessGracePeriod)) {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "template", "spec", "nodeReadinessGracePeriod"),
+				"is not supported in MachinePool templates",
+			),
+		)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("MachinePool").GroupKind(), m.Name, allErrs)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMachinePoolNodeReadinessGracePeriodValidation(t *testing.T) {
+	g := NewWithT(t)
+
+	mp := &MachinePool{}
+	g.Expect(mp.ValidateCreate()).To(Succeed())
+	g.Expect(mp.ValidateUpdate(mp.DeepCopy())).To(Succeed())
+
+	old := mp.DeepCopy()
+	mp.Spec.Template.Spec.NodeReadinessGracePeriod = &metav1.Duration{Duration: time.Minute}
+	g.Expect(mp.ValidateCreate()).NotTo(Succeed())
+	g.Expect(mp.ValidateUpdate(old)).NotTo(Succeed())
+
+	// MachinePools admitted with a grace period can still be updated.
+	g.Expect(mp.ValidateUpdate(mp.DeepCopy())).To(Succeed())
+}
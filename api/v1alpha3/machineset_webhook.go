@@ -24,11 +24,13 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 func (m *MachineSet) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	referenceReader = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(m).
 		Complete()
@@ -40,12 +42,20 @@ var _ webhook.Validator = &MachineSet{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachineSet) ValidateCreate() error {
-	return m.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("MachineSet"), capiwebhook.OperationCreate, m, func() error {
+		return m.validate(nil)
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (m *MachineSet) ValidateUpdate(old runtime.Object) error {
-	return m.validate()
+	return capiwebhook.Validate(GroupVersion.WithKind("MachineSet"), capiwebhook.OperationUpdate, m, func() error {
+		oldMS, ok := old.(*MachineSet)
+		if !ok {
+			return apierrors.NewBadRequest(fmt.Sprintf("expected a MachineSet but got a %T", old))
+		}
+		return m.validate(oldMS)
+	})
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -53,7 +63,7 @@ func (m *MachineSet) ValidateDelete() error {
 	return nil
 }
 
-func (m *MachineSet) validate() error {
+func (m *MachineSet) validate(old *MachineSet) error {
 	var allErrs field.ErrorList
 	selector, err := metav1.LabelSelectorAsSelector(&m.Spec.Selector)
 	if err != nil {
@@ -72,6 +82,13 @@ func (m *MachineSet) validate() error {
 		)
 	}
 
+	var oldSpec *MachineSpec
+	if old != nil {
+		oldSpec = &old.Spec.Template.Spec
+	}
+	allErrs = append(allErrs, validateReferencesNotSwapped(&m.Spec.Template.Spec, oldSpec, field.NewPath("spec", "template", "spec"))...)
+	allErrs = append(allErrs, validateNodeReadinessGracePeriod(&m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -79,12 +80,39 @@ func TestMachineSetLabelSelectorMatchValidation(t *testing.T) {
 			}
 			if tt.expectErr {
 				g.Expect(ms.ValidateCreate()).NotTo(Succeed())
-				g.Expect(ms.ValidateUpdate(nil)).NotTo(Succeed())
+				g.Expect(ms.ValidateUpdate(ms)).NotTo(Succeed())
 			} else {
 				g.Expect(ms.ValidateCreate()).To(Succeed())
-				g.Expect(ms.ValidateUpdate(nil)).To(Succeed())
+				g.Expect(ms.ValidateUpdate(ms)).To(Succeed())
 			}
 		})
 	}
 
 }
+
+func TestMachineSetReferencesSwappedValidation(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &MachineSet{
+		Spec: MachineSetSpec{
+			Template: MachineTemplateSpec{
+				Spec: MachineSpec{
+					Bootstrap: Bootstrap{
+						ConfigRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3", Kind: "AWSMachineTemplate"},
+					},
+					InfrastructureRef: corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3", Kind: "KubeadmConfigTemplate"},
+				},
+			},
+		},
+	}
+	g.Expect(ms.ValidateCreate()).NotTo(Succeed())
+	// References that aren't changed by an update are not checked again.
+	g.Expect(ms.ValidateUpdate(ms.DeepCopy())).To(Succeed())
+
+	old := ms.DeepCopy()
+	ms.Spec.Template.Spec.Bootstrap.ConfigRef, ms.Spec.Template.Spec.InfrastructureRef =
+		ms.Spec.Template.Spec.InfrastructureRef.DeepCopy(), *ms.Spec.Template.Spec.Bootstrap.ConfigRef
+	g.Expect(ms.ValidateCreate()).To(Succeed())
+	g.Expect(ms.ValidateUpdate(old)).To(Succeed())
+	g.Expect(old.ValidateUpdate(ms)).NotTo(Succeed())
+}
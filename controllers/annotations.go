@@ -0,0 +1,42 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// markAnnotationsSkipped reports the annotations of obj that aren't copied to the target because of the size
+// limit with the AnnotationsPropagated condition and an event. The condition is marked true again once no
+// annotation is skipped.
+func markAnnotationsSkipped(recorder record.EventRecorder, obj conditions.Setter, skipped []string, target string) {
+	if len(skipped) == 0 {
+		if conditions.Has(obj, clusterv1.AnnotationsPropagatedCondition) {
+			conditions.MarkTrue(obj, clusterv1.AnnotationsPropagatedCondition)
+		}
+		return
+	}
+
+	conditions.MarkFalseWithEvent(recorder, obj, clusterv1.AnnotationsPropagatedCondition, clusterv1.AnnotationsSkippedReason,
+		clusterv1.ConditionSeverityWarning, "Not copying annotations %s to %s: annotations are limited to %d bytes",
+		strings.Join(skipped, ", "), target, util.MaxAnnotationsSize)
+}
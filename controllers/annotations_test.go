@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestMarkAnnotationsSkipped(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(32)
+	ms := &clusterv1.MachineSet{}
+
+	// Nothing is reported while all the annotations are copied.
+	markAnnotationsSkipped(recorder, ms, nil, "new Machines")
+	g.Expect(conditions.Has(ms, clusterv1.AnnotationsPropagatedCondition)).To(BeFalse())
+	g.Expect(recorder.Events).NotTo(Receive())
+
+	// Skipped annotations are reported once, rather than on every reconcile.
+	markAnnotationsSkipped(recorder, ms, []string{"large"}, "new Machines")
+	markAnnotationsSkipped(recorder, ms, []string{"large"}, "new Machines")
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("Not copying annotations large to new Machines")))
+	g.Expect(recorder.Events).NotTo(Receive())
+	c := conditions.Get(ms, clusterv1.AnnotationsPropagatedCondition)
+	g.Expect(c).NotTo(BeNil())
+	g.Expect(c.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(c.Reason).To(Equal(clusterv1.AnnotationsSkippedReason))
+
+	// They're reported again when the skipped annotations change.
+	markAnnotationsSkipped(recorder, ms, []string{"large", "larger"}, "new Machines")
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("Not copying annotations large, larger to new Machines")))
+
+	// The condition is marked true once no annotation is skipped anymore.
+	markAnnotationsSkipped(recorder, ms, nil, "new Machines")
+	g.Expect(conditions.IsTrue(ms, clusterv1.AnnotationsPropagatedCondition)).To(BeTrue())
+	g.Expect(recorder.Events).NotTo(Receive())
+}
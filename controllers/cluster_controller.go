@@ -36,13 +36,16 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/metrics"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conflict"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -64,9 +67,21 @@ type ClusterReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
+	// ExcludedNamespaces is the list of namespaces whose objects are ignored by the controller.
+	ExcludedNamespaces []string
+
+	// ConflictRequeueAfter is how long to wait before reconciling an object again after an update
+	// conflict. If zero, the object is requeued with the controller's rate limiter.
+	ConflictRequeueAfter time.Duration
+
+	// ProviderCRDRequeueAfter is how long to wait before reconciling an object again when the CRD
+	// of a referenced infrastructure or bootstrap object isn't installed. Defaults to one minute.
+	ProviderCRDRequeueAfter time.Duration
+
 	scheme          *runtime.Scheme
 	recorder        record.EventRecorder
 	externalTracker external.ObjectTracker
+	inventory       machineInventory
 }
 
 func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -76,8 +91,18 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 			&source.Kind{Type: &clusterv1.Machine{}},
 			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.controlPlaneMachineToCluster)},
 		).
+		Watches(
+			&source.Kind{Type: &clusterv1.Machine{}},
+			r.machineInventoryHandler(),
+		).
 		WithOptions(options).
-		Build(r)
+		WithEventFilter(predicates.ExcludeNamespaces(r.Log, r.ExcludedNamespaces)).
+		Build(&conflict.Reconciler{
+			Controller:   "cluster",
+			Reconciler:   r,
+			RequeueAfter: r.ConflictRequeueAfter,
+			Log:          r.Log,
+		})
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -87,6 +112,7 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 	r.scheme = mgr.GetScheme()
 	r.externalTracker = external.ObjectTracker{
 		Controller: controller,
+		Predicates: []predicate.Predicate{predicates.ExcludeNamespaces(r.Log, r.ExcludedNamespaces)},
 	}
 	return nil
 }
@@ -101,6 +127,7 @@ func (r *ClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr e
 		if apierrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			r.inventory.delete(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
@@ -174,7 +201,7 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 	return res, kerrors.NewAggregate(errs)
 }
 
-func (r *ClusterReconciler) reconcileMetrics(_ context.Context, cluster *clusterv1.Cluster) {
+func (r *ClusterReconciler) reconcileMetrics(ctx context.Context, cluster *clusterv1.Cluster) {
 
 	if cluster.Status.ControlPlaneInitialized {
 		metrics.ClusterControlPlaneReady.WithLabelValues(cluster.Name, cluster.Namespace).Set(1)
@@ -200,6 +227,15 @@ func (r *ClusterReconciler) reconcileMetrics(_ context.Context, cluster *cluster
 	} else {
 		metrics.ClusterFailureSet.WithLabelValues(cluster.Name, cluster.Namespace).Set(0)
 	}
+
+	// Drop the machine inventory of a deleted cluster once its descendants are gone.
+	if !cluster.DeletionTimestamp.IsZero() && !util.Contains(cluster.Finalizers, clusterv1.ClusterFinalizer) {
+		r.inventory.delete(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+		return
+	}
+	if err := r.reconcileMachineInventory(ctx, cluster); err != nil {
+		r.Log.Error(err, "Failed to reconcile the machine inventory", "cluster", cluster.Name, "namespace", cluster.Namespace)
+	}
 }
 
 // reconcileDelete handles cluster deletion.
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// machineInventoryKey identifies the capi_cluster_machine_inventory series a Machine is counted in.
+type machineInventoryKey struct {
+	instanceType string
+	zone         string
+}
+
+// machineInventoryKeyFor returns the inventory key of a Machine. The instance type and zone are read from the
+// labels set from the infrastructure object's status. For providers that don't report them, the instance type
+// falls back to the well-known instance type label and the zone to the Machine's failure domain.
+func machineInventoryKeyFor(m *clusterv1.Machine) machineInventoryKey {
+	key := machineInventoryKey{
+		instanceType: m.Labels[clusterv1.MachineInstanceTypeLabelName],
+		zone:         m.Labels[clusterv1.MachineZoneLabelName],
+	}
+	if key.instanceType == "" {
+		key.instanceType = m.Labels[corev1.LabelInstanceTypeStable]
+	}
+	if key.zone == "" && m.Spec.FailureDomain != nil {
+		key.zone = *m.Spec.FailureDomain
+	}
+	return key
+}
+
+// machineInventory keeps track of the capi_cluster_machine_inventory series set for each cluster,
+// so that series are deleted once no Machine of the cluster is counted in them anymore.
+type machineInventory struct {
+	lock   sync.Mutex
+	series map[types.NamespacedName]map[machineInventoryKey]struct{}
+}
+
+// set replaces the series of a cluster with the given counts.
+func (i *machineInventory) set(cluster types.NamespacedName, counts map[machineInventoryKey]int) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.series == nil {
+		i.series = make(map[types.NamespacedName]map[machineInventoryKey]struct{})
+	}
+
+	current := make(map[machineInventoryKey]struct{}, len(counts))
+	for key, count := range counts {
+		metrics.ClusterMachineInventory.WithLabelValues(cluster.Name, cluster.Namespace, key.instanceType, key.zone).Set(float64(count))
+		current[key] = struct{}{}
+	}
+	for key := range i.series[cluster] {
+		if _, ok := current[key]; !ok {
+			metrics.ClusterMachineInventory.DeleteLabelValues(cluster.Name, cluster.Namespace, key.instanceType, key.zone)
+		}
+	}
+
+	if len(current) == 0 {
+		delete(i.series, cluster)
+		return
+	}
+	i.series[cluster] = current
+}
+
+// delete removes all the series of a cluster.
+func (i *machineInventory) delete(cluster types.NamespacedName) {
+	i.set(cluster, nil)
+}
+
+// reconcileMachineInventory counts the Machines of a Cluster by instance type and zone.
+func (r *ClusterReconciler) reconcileMachineInventory(ctx context.Context, cluster *clusterv1.Cluster) error {
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	); err != nil {
+		return errors.Wrapf(err, "failed to list Machines for cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	counts := make(map[machineInventoryKey]int)
+	for i := range machines.Items {
+		counts[machineInventoryKeyFor(&machines.Items[i])]++
+	}
+	r.inventory.set(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, counts)
+	return nil
+}
+
+// machineInventoryHandler enqueues the Cluster of a Machine when the Machine is created or deleted,
+// or when its instance type or zone changes, so that the inventory stays current.
+func (r *ClusterReconciler) machineInventoryHandler() handler.EventHandler {
+	enqueue := func(o runtime.Object, q workqueue.RateLimitingInterface) {
+		m, ok := o.(*clusterv1.Machine)
+		if !ok || m.Spec.ClusterName == "" {
+			return
+		}
+		q.Add(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.ClusterName}})
+	}
+
+	return &handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(e.Object, q)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldMachine, ok := e.ObjectOld.(*clusterv1.Machine)
+			if !ok {
+				return
+			}
+			newMachine, ok := e.ObjectNew.(*clusterv1.Machine)
+			if !ok {
+				return
+			}
+			if machineInventoryKeyFor(oldMachine) != machineInventoryKeyFor(newMachine) {
+				enqueue(newMachine, q)
+			}
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			enqueue(e.Object, q)
+		},
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func TestClusterReconcileMachineInventory(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "inventory-cluster", Namespace: "default"}}
+	newMachine := func(name, instanceType string, failureDomain *string, labels map[string]string) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cluster.Namespace,
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName:             cluster.Name,
+					clusterv1.MachineInstanceTypeLabelName: instanceType,
+				},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: cluster.Name, FailureDomain: failureDomain},
+		}
+		for k, v := range labels {
+			m.Labels[k] = v
+		}
+		return m
+	}
+	machines := []*clusterv1.Machine{
+		newMachine("machine-1", "m5.large", pointer.StringPtr("us-east-1a"), nil),
+		newMachine("machine-2", "m5.large", pointer.StringPtr("us-east-1a"), nil),
+		newMachine("machine-3", "", pointer.StringPtr("us-east-1a"), map[string]string{
+			corev1.LabelInstanceTypeStable: "m5.xlarge",
+			clusterv1.MachineZoneLabelName: "us-east-1b",
+		}),
+	}
+
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster, machines[0], machines[1], machines[2])
+	r := &ClusterReconciler{
+		Client: c,
+		Log:    log.Log,
+	}
+
+	g.Expect(r.reconcileMachineInventory(context.Background(), cluster)).To(Succeed())
+	g.Expect(machineInventorySeries(g, cluster.Name)).To(Equal(map[machineInventoryKey]float64{
+		{instanceType: "m5.large", zone: "us-east-1a"}:  2,
+		{instanceType: "m5.xlarge", zone: "us-east-1b"}: 1,
+	}))
+
+	// The series of a Machine that went away is deleted.
+	g.Expect(c.Delete(context.Background(), machines[2])).To(Succeed())
+	g.Expect(r.reconcileMachineInventory(context.Background(), cluster)).To(Succeed())
+	g.Expect(machineInventorySeries(g, cluster.Name)).To(Equal(map[machineInventoryKey]float64{
+		{instanceType: "m5.large", zone: "us-east-1a"}: 2,
+	}))
+
+	// All the series of a deleted cluster are deleted.
+	r.inventory.delete(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+	g.Expect(machineInventorySeries(g, cluster.Name)).To(BeEmpty())
+}
+
+// machineInventorySeries returns the capi_cluster_machine_inventory series of a cluster.
+func machineInventorySeries(g *WithT, clusterName string) map[machineInventoryKey]float64 {
+	mfs, err := metrics.Registry.Gather()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	series := map[machineInventoryKey]float64{}
+	mf := getMetricFamily(mfs, "capi_cluster_machine_inventory")
+	if mf == nil {
+		return series
+	}
+	for _, m := range mf.GetMetric() {
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["cluster"] != clusterName {
+			continue
+		}
+		series[machineInventoryKey{instanceType: labels["instance_type"], zone: labels["zone"]}] = m.GetGauge().GetValue()
+	}
+	return series
+}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
@@ -69,8 +70,12 @@ func (r *ClusterReconciler) reconcileExternal(ctx context.Context, cluster *clus
 				"could not find %v %q for Cluster %q in namespace %q, requeuing",
 				ref.GroupVersionKind(), ref.Name, cluster.Name, cluster.Namespace)
 		}
+		if isProviderCRDNotInstalled(err) {
+			return external.ReconcileOutput{}, markProviderCRDNotInstalled(r.recorder, cluster, ref, r.ProviderCRDRequeueAfter)
+		}
 		return external.ReconcileOutput{}, err
 	}
+	markProviderCRDInstalled(cluster, ref)
 
 	// if external ref is paused, return error.
 	if util.IsPaused(cluster, obj) {
@@ -225,16 +230,15 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 		return nil
 	}
 
-	// Do not generate the Kubeconfig if there is a ControlPlaneRef, since the Control Plane provider is
-	// responsible for the management of the Kubeconfig. We continue to manage it here only for backward
-	// compatibility when a Control Plane provider is not in use.
-	if cluster.Spec.ControlPlaneRef != nil {
-		return nil
-	}
-
-	_, err := secret.Get(ctx, r.Client, cluster, secret.Kubeconfig)
+	kubeconfigSecret, err := secret.Get(ctx, r.Client, cluster, secret.Kubeconfig)
 	switch {
 	case apierrors.IsNotFound(err):
+		// Do not generate the Kubeconfig if there is a ControlPlaneRef, since the Control Plane provider is
+		// responsible for the management of the Kubeconfig. We continue to manage it here only for backward
+		// compatibility when a Control Plane provider is not in use.
+		if cluster.Spec.ControlPlaneRef != nil {
+			return nil
+		}
 		if err := kubeconfig.CreateSecret(ctx, r.Client, cluster); err != nil {
 			if err == kubeconfig.ErrDependentCertificateNotFound {
 				return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 30 * time.Second},
@@ -243,9 +247,19 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 			}
 			return err
 		}
+		return nil
 	case err != nil:
 		return errors.Wrapf(err, "failed to retrieve Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
 	}
 
+	// Verify the shape of the Kubeconfig secret, regardless of who manages it, so a malformed secret
+	// is surfaced here rather than as an obscure error every time a remote client is created.
+	if err := kubeconfig.ValidateSecret(kubeconfigSecret); err != nil {
+		conditions.MarkFalseWithEvent(r.recorder, cluster, clusterv1.KubeconfigReadyCondition, clusterv1.InvalidKubeconfigSecretReason,
+			clusterv1.ConditionSeverityError, "Kubeconfig secret %q is invalid: %v", kubeconfigSecret.Name, err)
+		return nil
+	}
+	conditions.MarkTrue(cluster, clusterv1.KubeconfigReadyCondition)
+
 	return nil
 }
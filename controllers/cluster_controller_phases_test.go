@@ -26,13 +26,32 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const testKubeconfig = `
+clusters:
+- cluster:
+    server: https://1.2.3.4:8443
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-cluster-admin
+  name: test-cluster-admin@test-cluster
+current-context: test-cluster-admin@test-cluster
+kind: Config
+users:
+- name: test-cluster-admin
+`
+
 func TestClusterReconcilePhases(t *testing.T) {
 	t.Run("reconcile infrastructure", func(t *testing.T) {
 		cluster := &clusterv1.Cluster{
@@ -161,11 +180,12 @@ func TestClusterReconcilePhases(t *testing.T) {
 		}
 
 		tests := []struct {
-			name        string
-			cluster     *clusterv1.Cluster
-			secret      *corev1.Secret
-			wantErr     bool
-			wantRequeue bool
+			name          string
+			cluster       *clusterv1.Cluster
+			secret        *corev1.Secret
+			wantErr       bool
+			wantRequeue   bool
+			wantCondition *clusterv1.Condition
 		}{
 			{
 				name:    "cluster not provisioned, apiEndpoint is not set",
@@ -179,8 +199,53 @@ func TestClusterReconcilePhases(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "test-cluster-kubeconfig",
 					},
+					Data: map[string][]byte{
+						secret.KubeconfigDataName: []byte(testKubeconfig),
+					},
+				},
+				wantErr: false,
+				wantCondition: &clusterv1.Condition{
+					Type:   clusterv1.KubeconfigReadyCondition,
+					Status: corev1.ConditionTrue,
+				},
+			},
+			{
+				name:    "kubeconfig secret found with the wrong data key, should set InvalidKubeconfigSecret",
+				cluster: cluster,
+				secret: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-cluster-kubeconfig",
+					},
+					Data: map[string][]byte{
+						"kubeconfig": []byte(testKubeconfig),
+					},
 				},
 				wantErr: false,
+				wantCondition: &clusterv1.Condition{
+					Type:     clusterv1.KubeconfigReadyCondition,
+					Status:   corev1.ConditionFalse,
+					Severity: clusterv1.ConditionSeverityError,
+					Reason:   clusterv1.InvalidKubeconfigSecretReason,
+				},
+			},
+			{
+				name:    "kubeconfig secret found with unparsable data, should set InvalidKubeconfigSecret",
+				cluster: cluster,
+				secret: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-cluster-kubeconfig",
+					},
+					Data: map[string][]byte{
+						secret.KubeconfigDataName: []byte("clusters: ["),
+					},
+				},
+				wantErr: false,
+				wantCondition: &clusterv1.Condition{
+					Type:     clusterv1.KubeconfigReadyCondition,
+					Status:   corev1.ConditionFalse,
+					Severity: clusterv1.ConditionSeverityError,
+					Reason:   clusterv1.InvalidKubeconfigSecretReason,
+				},
 			},
 			{
 				name:        "kubeconfig secret not found, should return RequeueAfterError",
@@ -204,15 +269,18 @@ func TestClusterReconcilePhases(t *testing.T) {
 				g := NewWithT(t)
 				g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
 
-				c := fake.NewFakeClientWithScheme(scheme.Scheme, tt.cluster)
+				cluster := tt.cluster.DeepCopy()
+				c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster)
 				if tt.secret != nil {
-					c = fake.NewFakeClientWithScheme(scheme.Scheme, tt.cluster, tt.secret)
+					c = fake.NewFakeClientWithScheme(scheme.Scheme, cluster, tt.secret)
 				}
+				recorder := record.NewFakeRecorder(32)
 				r := &ClusterReconciler{
-					Client: c,
-					scheme: scheme.Scheme,
+					Client:   c,
+					scheme:   scheme.Scheme,
+					recorder: recorder,
 				}
-				err := r.reconcileKubeconfig(context.Background(), tt.cluster)
+				err := r.reconcileKubeconfig(context.Background(), cluster)
 				if tt.wantErr {
 					g.Expect(err).To(HaveOccurred())
 				} else {
@@ -220,6 +288,23 @@ func TestClusterReconcilePhases(t *testing.T) {
 				}
 
 				g.Expect(capierrors.IsRequeueAfter(err)).To(Equal(tt.wantRequeue))
+
+				if tt.wantCondition == nil {
+					g.Expect(conditions.Has(cluster, clusterv1.KubeconfigReadyCondition)).To(BeFalse())
+					return
+				}
+				got := conditions.Get(cluster, clusterv1.KubeconfigReadyCondition)
+				g.Expect(got).NotTo(BeNil())
+				g.Expect(got.Status).To(Equal(tt.wantCondition.Status))
+				g.Expect(got.Severity).To(Equal(tt.wantCondition.Severity))
+				g.Expect(got.Reason).To(Equal(tt.wantCondition.Reason))
+				if tt.wantCondition.Status == corev1.ConditionFalse {
+					g.Expect(recorder.Events).To(Receive(ContainSubstring(clusterv1.InvalidKubeconfigSecretReason)))
+
+					// A second reconcile with the same problem must not emit a duplicate Event.
+					g.Expect(r.reconcileKubeconfig(context.Background(), cluster)).To(Succeed())
+					g.Expect(recorder.Events).NotTo(Receive())
+				}
 			})
 		}
 	})
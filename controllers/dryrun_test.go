@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMachineReconcilerDryRun(t *testing.T) {
+	r := &MachineReconciler{}
+	expectDryRunKeepsSettings(t, r, r.DryRun)
+}
+
+func TestMachineDeploymentReconcilerDryRun(t *testing.T) {
+	r := &MachineDeploymentReconciler{}
+	expectDryRunKeepsSettings(t, r, r.DryRun)
+}
+
+// expectDryRunKeepsSettings sets every exported field of the reconciler other than Client and Log, and expects
+// the dry-run reconciler to have the same settings, so that fields added later can't be missed by DryRun.
+func expectDryRunKeepsSettings(t *testing.T, r reconcile.Reconciler, dryRun func(client.Client) reconcile.Reconciler) {
+	g := NewWithT(t)
+
+	v := reflect.ValueOf(r).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" || field.Name == "Client" || field.Name == "Log" {
+			continue
+		}
+		switch value := v.Field(i); value.Interface().(type) {
+		case []string:
+			value.Set(reflect.ValueOf([]string{"excluded"}))
+		case time.Duration:
+			value.Set(reflect.ValueOf(time.Duration(i+1) * time.Second))
+		case bool:
+			value.SetBool(true)
+		default:
+			t.Fatalf("field %s has type %s, which the test doesn't know how to set", field.Name, field.Type)
+		}
+	}
+	v.FieldByName("Log").Set(reflect.ValueOf(log.Log))
+
+	c := fake.NewFakeClient()
+	dr := reflect.ValueOf(dryRun(c)).Elem()
+	g.Expect(dr.FieldByName("Client").Interface()).To(BeIdenticalTo(c))
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" || field.Name == "Client" || field.Name == "Log" {
+			continue
+		}
+		g.Expect(dr.Field(i).Interface()).To(Equal(v.Field(i).Interface()), field.Name)
+	}
+}
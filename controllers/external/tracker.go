@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
@@ -32,6 +33,9 @@ type ObjectTracker struct {
 	m sync.Map
 
 	Controller controller.Controller
+
+	// Predicates are applied to the events of the watched external objects.
+	Predicates []predicate.Predicate
 }
 
 // Watch uses the controller to issue a Watch only if the object hasn't been seen before.
@@ -51,6 +55,7 @@ func (o *ObjectTracker) Watch(log logr.Logger, obj runtime.Object, handler handl
 	err := o.Controller.Watch(
 		&source.Kind{Type: obj},
 		handler,
+		o.Predicates...,
 	)
 	if err != nil {
 		o.m.Delete(obj)
@@ -42,11 +42,15 @@ import (
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	kubedrain "sigs.k8s.io/cluster-api/third_party/kubernetes-drain"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conflict"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var (
@@ -66,17 +70,35 @@ type MachineReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
+	// ExcludedNamespaces is the list of namespaces whose objects are ignored by the controller.
+	ExcludedNamespaces []string
+
+	// ConflictRequeueAfter is how long to wait before reconciling an object again after an update
+	// conflict. If zero, the object is requeued with the controller's rate limiter.
+	ConflictRequeueAfter time.Duration
+
+	// ProviderCRDRequeueAfter is how long to wait before reconciling an object again when the CRD
+	// of a referenced infrastructure or bootstrap object isn't installed. Defaults to one minute.
+	ProviderCRDRequeueAfter time.Duration
+
 	config          *rest.Config
 	scheme          *runtime.Scheme
 	recorder        record.EventRecorder
 	externalTracker external.ObjectTracker
+	clusterClients  *remote.ClusterClientCache
 }
 
 func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	controller, err := ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1.Machine{}).
 		WithOptions(options).
-		Build(r)
+		WithEventFilter(predicates.ExcludeNamespaces(r.Log, r.ExcludedNamespaces)).
+		Build(&conflict.Reconciler{
+			Controller:   "machine",
+			Reconciler:   r,
+			RequeueAfter: r.ConflictRequeueAfter,
+			Log:          r.Log,
+		})
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -87,10 +109,29 @@ func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 	r.scheme = mgr.GetScheme()
 	r.externalTracker = external.ObjectTracker{
 		Controller: controller,
+		Predicates: []predicate.Predicate{predicates.ExcludeNamespaces(r.Log, r.ExcludedNamespaces)},
 	}
+	r.clusterClients = &remote.ClusterClientCache{}
 	return nil
 }
 
+// DryRun returns a reconciler with the same settings, using the given client for all its reads and writes and
+// discarding its events. It doesn't watch external objects. It's meant to be used with a dry-run client to preview
+// the changes a reconcile would apply.
+func (r *MachineReconciler) DryRun(c client.Client) reconcile.Reconciler {
+	return &MachineReconciler{
+		Client:                  c,
+		Log:                     r.Log.WithValues("dry-run", true),
+		ExcludedNamespaces:      r.ExcludedNamespaces,
+		ConflictRequeueAfter:    r.ConflictRequeueAfter,
+		ProviderCRDRequeueAfter: r.ProviderCRDRequeueAfter,
+		config:                  r.config,
+		scheme:                  r.scheme,
+		recorder:                &record.FakeRecorder{},
+		clusterClients:          r.clusterClients,
+	}
+}
+
 func (r *MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx := context.Background()
 	logger := r.Log.WithValues("machine", req.Name, "namespace", req.Namespace)
@@ -168,12 +209,15 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 	// If the Machine doesn't have a finalizer, add one.
 	controllerutil.AddFinalizer(m, clusterv1.MachineFinalizer)
 
-	// Call the inner reconciliation methods.
+	// Call the inner reconciliation methods. The ones checking the Node share a client for the workload cluster.
+	getClusterClient := r.newClusterClientGetter(ctx, cluster)
 	reconciliationErrors := []error{
 		r.reconcileBootstrap(ctx, cluster, m),
 		r.reconcileInfrastructure(ctx, cluster, m),
-		r.reconcileNodeRef(ctx, cluster, m),
+		r.reconcileNodeRef(ctx, cluster, m, getClusterClient),
+		r.reconcileKubeletVersion(ctx, m, getClusterClient),
 	}
+	nodeReadinessResult := r.reconcileNodeReadiness(ctx, m, getClusterClient)
 
 	// Parse the errors, making sure we record if there is a RequeueAfterError.
 	res := ctrl.Result{}
@@ -191,6 +235,12 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 
 		errs = append(errs, err)
 	}
+
+	// Requeue when the Node readiness grace period elapses, unless an earlier requeue is already asked for.
+	if nodeReadinessResult.RequeueAfter > 0 && (res.RequeueAfter == 0 || nodeReadinessResult.RequeueAfter < res.RequeueAfter) {
+		res.Requeue = true
+		res.RequeueAfter = nodeReadinessResult.RequeueAfter
+	}
 	return res, kerrors.NewAggregate(errs)
 }
 
@@ -18,14 +18,18 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
-	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -33,15 +37,36 @@ var (
 	ErrNodeNotFound = errors.New("cannot find node with matching ProviderID")
 )
 
-func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
+// clusterClientGetter returns a client for a workload cluster.
+type clusterClientGetter func() (client.Client, error)
+
+// newClusterClientGetter returns a clusterClientGetter getting the client for the workload cluster of the
+// given Cluster on first use, so the checks run against the Node of a Machine share a single client.
+// The client itself is cached across reconciles, and only created again when the kubeconfig changes.
+func (r *MachineReconciler) newClusterClientGetter(ctx context.Context, cluster *clusterv1.Cluster) clusterClientGetter {
+	var (
+		once sync.Once
+		c    client.Client
+		err  error
+	)
+	return func() (client.Client, error) {
+		once.Do(func() {
+			c, err = r.clusterClients.Get(ctx, r.Client, cluster, r.scheme)
+		})
+		return c, err
+	}
+}
+
+func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine, getClusterClient clusterClientGetter) error {
 	logger := r.Log.WithValues("machine", machine.Name, "namespace", machine.Namespace)
 	// Check that the Machine hasn't been deleted or in the process.
 	if !machine.DeletionTimestamp.IsZero() {
 		return nil
 	}
 
-	// Check that the Machine doesn't already have a NodeRef.
-	if machine.Status.NodeRef != nil {
+	// Check that the Machine doesn't already have a NodeRef, or that the Node it references
+	// can be checked against the Machine's ProviderID.
+	if machine.Status.NodeRef != nil && (cluster == nil || machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "") {
 		return nil
 	}
 
@@ -61,14 +86,26 @@ func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clust
 
 	providerID, err := noderefutil.NewProviderID(*machine.Spec.ProviderID)
 	if err != nil {
+		if machine.Status.NodeRef != nil {
+			return nil
+		}
 		return err
 	}
 
-	clusterClient, err := remote.NewClusterClient(ctx, r.Client, cluster, r.scheme)
+	clusterClient, err := getClusterClient()
 	if err != nil {
+		if machine.Status.NodeRef != nil {
+			logger.V(4).Info("Unable to reach the workload cluster, skipping NodeRef consistency check", "error", err.Error())
+			return nil
+		}
 		return err
 	}
 
+	// Keep the existing NodeRef unless it's stale, in which case it's resolved again below.
+	if machine.Status.NodeRef != nil && !r.clearStaleNodeRef(ctx, clusterClient, machine, providerID) {
+		return nil
+	}
+
 	// Get the Node reference.
 	nodeRef, err := r.getNodeReference(clusterClient, providerID)
 	if err != nil {
@@ -85,9 +122,45 @@ func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clust
 	machine.Status.NodeRef = nodeRef
 	logger.Info("Set Machine's NodeRef", "noderef", machine.Status.NodeRef.Name)
 	r.recorder.Event(machine, apicorev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
+	if conditions.Has(machine, clusterv1.NodeRefConsistentCondition) {
+		conditions.MarkTrue(machine, clusterv1.NodeRefConsistentCondition)
+	}
 	return nil
 }
 
+// clearStaleNodeRef clears the NodeRef of a Machine if the referenced Node reports a ProviderID other than the
+// Machine's, e.g. after the Node was recreated on a reused instance, and reports it with the NodeRefConsistent
+// condition. It returns true if the NodeRef was cleared. Nodes that can't be read or don't report a ProviderID
+// yet are not considered stale.
+func (r *MachineReconciler) clearStaleNodeRef(ctx context.Context, c client.Client, machine *clusterv1.Machine, providerID *noderefutil.ProviderID) bool {
+	logger := r.Log.WithValues("machine", machine.Name, "namespace", machine.Namespace, "node", machine.Status.NodeRef.Name)
+
+	node := &apicorev1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node); err != nil {
+		logger.V(4).Info("Unable to get Node, skipping NodeRef consistency check", "error", err.Error())
+		return false
+	}
+	if node.Spec.ProviderID == "" {
+		return false
+	}
+
+	nodeProviderID, err := noderefutil.NewProviderID(node.Spec.ProviderID)
+	if err != nil {
+		logger.V(4).Info("Unable to parse Node ProviderID, skipping NodeRef consistency check", "providerID", node.Spec.ProviderID)
+		return false
+	}
+	if providerID.Equals(nodeProviderID) {
+		return false
+	}
+
+	message := fmt.Sprintf("Node %s has ProviderID %s, but the Machine ProviderID is %s", node.Name, node.Spec.ProviderID, *machine.Spec.ProviderID)
+	logger.Info("Clearing stale NodeRef", "node-provider-id", node.Spec.ProviderID, "provider-id", *machine.Spec.ProviderID)
+	r.recorder.Event(machine, apicorev1.EventTypeWarning, clusterv1.NodeRefStaleReason, message)
+	conditions.MarkFalse(machine, clusterv1.NodeRefConsistentCondition, clusterv1.NodeRefStaleReason, clusterv1.ConditionSeverityWarning, "%s", message)
+	machine.Status.NodeRef = nil
+	return true
+}
+
 func (r *MachineReconciler) getNodeReference(c client.Client, providerID *noderefutil.ProviderID) (*apicorev1.ObjectReference, error) {
 	logger := r.Log.WithValues("providerID", providerID)
 
@@ -121,3 +194,133 @@ func (r *MachineReconciler) getNodeReference(c client.Client, providerID *nodere
 
 	return nil, ErrNodeNotFound
 }
+
+// reconcileKubeletVersion compares the kubelet version reported by the Node backing the Machine with
+// the version defined in the Machine spec, and reports a mismatch with the KubeletVersionMatches condition.
+// When the workload cluster can't be reached the condition is left untouched, so it doesn't flap.
+func (r *MachineReconciler) reconcileKubeletVersion(ctx context.Context, machine *clusterv1.Machine, getClusterClient clusterClientGetter) error {
+	if machine.Spec.Version == nil {
+		conditions.Delete(machine, clusterv1.KubeletVersionMatchesCondition)
+	}
+	if !machine.DeletionTimestamp.IsZero() || machine.Spec.Version == nil || machine.Status.NodeRef == nil {
+		return nil
+	}
+
+	clusterClient, err := getClusterClient()
+	if err != nil {
+		r.Log.V(4).Info("Unable to reach the workload cluster, skipping kubelet version check",
+			"machine", machine.Name, "namespace", machine.Namespace, "error", err.Error())
+		return nil
+	}
+
+	r.setKubeletVersionCondition(ctx, clusterClient, machine)
+	return nil
+}
+
+func (r *MachineReconciler) setKubeletVersionCondition(ctx context.Context, c client.Client, machine *clusterv1.Machine) {
+	logger := r.Log.WithValues("machine", machine.Name, "namespace", machine.Namespace, "node", machine.Status.NodeRef.Name)
+
+	node := &apicorev1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node); err != nil {
+		logger.V(4).Info("Unable to get Node, skipping kubelet version check", "error", err.Error())
+		return
+	}
+
+	kubeletVersion := node.Status.NodeInfo.KubeletVersion
+	if kubeletVersion == "" {
+		return
+	}
+
+	desired, err := version.ParseGeneric(*machine.Spec.Version)
+	if err != nil {
+		logger.V(4).Info("Unable to parse Machine version, skipping kubelet version check", "version", *machine.Spec.Version)
+		return
+	}
+	actual, err := version.ParseGeneric(kubeletVersion)
+	if err != nil {
+		logger.V(4).Info("Unable to parse kubelet version, skipping kubelet version check", "version", kubeletVersion)
+		return
+	}
+
+	// Only major, minor and patch are compared, as distributions may add their own suffixes to the kubelet version.
+	if desired.Major() == actual.Major() && desired.Minor() == actual.Minor() && desired.Patch() == actual.Patch() {
+		conditions.MarkTrue(machine, clusterv1.KubeletVersionMatchesCondition)
+		return
+	}
+
+	logger.V(4).Info("Kubelet version doesn't match the Machine version", "kubelet-version", kubeletVersion, "version", *machine.Spec.Version)
+	conditions.MarkFalseWithEvent(r.recorder, machine, clusterv1.KubeletVersionMatchesCondition, clusterv1.KubeletVersionMismatchReason, clusterv1.ConditionSeverityWarning,
+		"Node %s runs kubelet %s, but the Machine version is %s", node.Name, kubeletVersion, *machine.Spec.Version)
+}
+
+// reconcileNodeReadiness records the time the Node backing the Machine became Ready and, when the Machine
+// defines a readiness grace period, reports with the NodeReady condition whether the Node has been Ready
+// continuously for that long, returning a result requeueing the Machine until it has. Waiting for the Node
+// is expected, so it isn't reported as an error. When the workload cluster can't be reached the status is
+// left untouched, so it doesn't flap.
+func (r *MachineReconciler) reconcileNodeReadiness(ctx context.Context, machine *clusterv1.Machine, getClusterClient clusterClientGetter) ctrl.Result {
+	if machine.Spec.NodeReadinessGracePeriod == nil {
+		conditions.Delete(machine, clusterv1.NodeReadyCondition)
+	}
+	if !machine.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}
+	}
+	if machine.Status.NodeRef == nil {
+		machine.Status.NodeReadyTime = nil
+		return ctrl.Result{}
+	}
+
+	clusterClient, err := getClusterClient()
+	if err != nil {
+		r.Log.V(4).Info("Unable to reach the workload cluster, skipping Node readiness check",
+			"machine", machine.Name, "namespace", machine.Namespace, "error", err.Error())
+		return ctrl.Result{}
+	}
+
+	return r.setNodeReadyCondition(ctx, clusterClient, machine)
+}
+
+func (r *MachineReconciler) setNodeReadyCondition(ctx context.Context, c client.Client, machine *clusterv1.Machine) ctrl.Result {
+	logger := r.Log.WithValues("machine", machine.Name, "namespace", machine.Namespace, "node", machine.Status.NodeRef.Name)
+
+	node := &apicorev1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node); err != nil {
+		logger.V(4).Info("Unable to get Node, skipping Node readiness check", "error", err.Error())
+		return ctrl.Result{}
+	}
+
+	// The Node's Ready condition is the source of truth for how long the Node has been Ready, so the
+	// grace period isn't restarted when the controller restarts; the time is only recorded here as a
+	// fallback for Nodes not reporting a transition time.
+	readyCondition := noderefutil.GetReadyCondition(&node.Status)
+	switch {
+	case readyCondition == nil || readyCondition.Status != apicorev1.ConditionTrue:
+		machine.Status.NodeReadyTime = nil
+	case !readyCondition.LastTransitionTime.IsZero():
+		machine.Status.NodeReadyTime = readyCondition.LastTransitionTime.DeepCopy()
+	default:
+		machine.Status.NodeReadyTime = readyTime(true, machine.Status.NodeReadyTime)
+	}
+
+	if machine.Spec.NodeReadinessGracePeriod == nil {
+		return ctrl.Result{}
+	}
+	gracePeriod := machine.Spec.NodeReadinessGracePeriod.Duration
+
+	if machine.Status.NodeReadyTime == nil {
+		conditions.MarkFalse(machine, clusterv1.NodeReadyCondition, clusterv1.NodeNotReadyReason, clusterv1.ConditionSeverityWarning,
+			"Node %s is not Ready", node.Name)
+		logger.V(4).Info("Waiting for Node to be Ready")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}
+	}
+
+	if remaining := time.Until(machine.Status.NodeReadyTime.Add(gracePeriod)); remaining > 0 {
+		conditions.MarkFalse(machine, clusterv1.NodeReadyCondition, clusterv1.WaitingForNodeReadinessGracePeriodReason, clusterv1.ConditionSeverityInfo,
+			"Node %s is Ready since %s, waiting for the %s grace period to elapse", node.Name, machine.Status.NodeReadyTime.UTC().Format(time.RFC3339), gracePeriod)
+		logger.V(4).Info("Waiting for Node readiness grace period to elapse", "remaining", remaining)
+		return ctrl.Result{RequeueAfter: remaining}
+	}
+
+	conditions.MarkTrue(machine, clusterv1.NodeReadyCondition)
+	return ctrl.Result{}
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -25,11 +27,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestGetNodeReference(t *testing.T) {
@@ -125,3 +130,337 @@ func TestGetNodeReference(t *testing.T) {
 
 	}
 }
+
+func TestSetKubeletVersionCondition(t *testing.T) {
+	node := func(kubeletVersion string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		node          *corev1.Node
+		existing      *clusterv1.Condition
+		wantCondition *clusterv1.Condition
+		wantEvent     bool
+	}{
+		{
+			name: "kubelet version matches",
+			node: node("v1.17.3"),
+			wantCondition: &clusterv1.Condition{
+				Type:   clusterv1.KubeletVersionMatchesCondition,
+				Status: corev1.ConditionTrue,
+			},
+		},
+		{
+			name: "kubelet version matches ignoring distribution suffix",
+			node: node("v1.17.3-eks-abc123"),
+			wantCondition: &clusterv1.Condition{
+				Type:   clusterv1.KubeletVersionMatchesCondition,
+				Status: corev1.ConditionTrue,
+			},
+		},
+		{
+			name: "kubelet version doesn't match",
+			node: node("v1.16.8"),
+			wantCondition: &clusterv1.Condition{
+				Type:     clusterv1.KubeletVersionMatchesCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityWarning,
+				Reason:   clusterv1.KubeletVersionMismatchReason,
+				Message:  "Node node-1 runs kubelet v1.16.8, but the Machine version is v1.17.3",
+			},
+			wantEvent: true,
+		},
+		{
+			name: "kubelet version mismatch already reported",
+			node: node("v1.16.8"),
+			existing: conditions.FalseCondition(clusterv1.KubeletVersionMatchesCondition, clusterv1.KubeletVersionMismatchReason, clusterv1.ConditionSeverityWarning,
+				"Node node-1 runs kubelet v1.16.8, but the Machine version is v1.17.3"),
+			wantCondition: &clusterv1.Condition{
+				Type:     clusterv1.KubeletVersionMatchesCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityWarning,
+				Reason:   clusterv1.KubeletVersionMismatchReason,
+				Message:  "Node node-1 runs kubelet v1.16.8, but the Machine version is v1.17.3",
+			},
+			wantEvent: false,
+		},
+		{
+			name:     "node not found, condition is left untouched",
+			existing: conditions.FalseCondition(clusterv1.KubeletVersionMatchesCondition, clusterv1.KubeletVersionMismatchReason, clusterv1.ConditionSeverityWarning, "mismatch"),
+			wantCondition: &clusterv1.Condition{
+				Type:     clusterv1.KubeletVersionMatchesCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityWarning,
+				Reason:   clusterv1.KubeletVersionMismatchReason,
+				Message:  "mismatch",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default"},
+				Spec:       clusterv1.MachineSpec{Version: pointer.StringPtr("v1.17.3")},
+				Status: clusterv1.MachineStatus{
+					NodeRef: &corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+				},
+			}
+			if tt.existing != nil {
+				conditions.Set(machine, tt.existing)
+			}
+
+			objs := []runtime.Object{}
+			if tt.node != nil {
+				objs = append(objs, tt.node)
+			}
+			recorder := record.NewFakeRecorder(32)
+			r := &MachineReconciler{
+				Log:      log.Log,
+				recorder: recorder,
+			}
+
+			r.setKubeletVersionCondition(context.Background(), fake.NewFakeClientWithScheme(scheme.Scheme, objs...), machine)
+
+			got := conditions.Get(machine, clusterv1.KubeletVersionMatchesCondition)
+			g.Expect(got).NotTo(BeNil())
+			g.Expect(got.Status).To(Equal(tt.wantCondition.Status))
+			g.Expect(got.Severity).To(Equal(tt.wantCondition.Severity))
+			g.Expect(got.Reason).To(Equal(tt.wantCondition.Reason))
+			g.Expect(got.Message).To(Equal(tt.wantCondition.Message))
+			if tt.wantEvent {
+				g.Expect(recorder.Events).To(Receive(ContainSubstring(clusterv1.KubeletVersionMismatchReason)))
+			} else {
+				g.Expect(recorder.Events).NotTo(Receive())
+			}
+		})
+	}
+}
+
+func TestReconcileKubeletVersionWithoutVersion(t *testing.T) {
+	g := NewWithT(t)
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default"},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+		},
+	}
+	conditions.MarkFalse(machine, clusterv1.KubeletVersionMatchesCondition, clusterv1.KubeletVersionMismatchReason, clusterv1.ConditionSeverityWarning, "mismatch")
+
+	r := &MachineReconciler{Log: log.Log}
+	getClusterClient := func() (client.Client, error) {
+		t.Fatal("the workload cluster shouldn't be reached when the Machine doesn't define a version")
+		return nil, nil
+	}
+
+	g.Expect(r.reconcileKubeletVersion(context.Background(), machine, getClusterClient)).To(Succeed())
+	g.Expect(conditions.Has(machine, clusterv1.KubeletVersionMatchesCondition)).To(BeFalse())
+}
+
+func TestClearStaleNodeRef(t *testing.T) {
+	tests := []struct {
+		name        string
+		node        *corev1.Node
+		wantCleared bool
+	}{
+		{
+			name: "Node reporting the Machine ProviderID",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec:       corev1.NodeSpec{ProviderID: "aws://us-east-1/id-node-1"},
+			},
+		},
+		{
+			name: "Node not reporting a ProviderID yet",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			},
+		},
+		{
+			name: "Node not found",
+		},
+		{
+			name: "Node reporting another ProviderID",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec:       corev1.NodeSpec{ProviderID: "aws://us-east-1/id-node-2"},
+			},
+			wantCleared: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default"},
+				Spec:       clusterv1.MachineSpec{ProviderID: pointer.StringPtr("aws:///id-node-1")},
+				Status: clusterv1.MachineStatus{
+					NodeRef: &corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+				},
+			}
+			providerID, err := noderefutil.NewProviderID(*machine.Spec.ProviderID)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			objs := []runtime.Object{}
+			if tt.node != nil {
+				objs = append(objs, tt.node)
+			}
+			recorder := record.NewFakeRecorder(32)
+			r := &MachineReconciler{
+				Log:      log.Log,
+				recorder: recorder,
+			}
+
+			cleared := r.clearStaleNodeRef(context.Background(), fake.NewFakeClientWithScheme(scheme.Scheme, objs...), machine, providerID)
+			g.Expect(cleared).To(Equal(tt.wantCleared))
+			if !tt.wantCleared {
+				g.Expect(machine.Status.NodeRef).NotTo(BeNil())
+				g.Expect(conditions.Has(machine, clusterv1.NodeRefConsistentCondition)).To(BeFalse())
+				g.Expect(recorder.Events).NotTo(Receive())
+				return
+			}
+
+			g.Expect(machine.Status.NodeRef).To(BeNil())
+			got := conditions.Get(machine, clusterv1.NodeRefConsistentCondition)
+			g.Expect(got).NotTo(BeNil())
+			g.Expect(got.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(got.Reason).To(Equal(clusterv1.NodeRefStaleReason))
+			g.Expect(got.Message).To(ContainSubstring("aws://us-east-1/id-node-2"))
+			g.Expect(recorder.Events).To(Receive(ContainSubstring(clusterv1.NodeRefStaleReason)))
+		})
+	}
+}
+
+func TestSetNodeReadyCondition(t *testing.T) {
+	readyNode := func(status corev1.ConditionStatus, since time.Duration) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:               corev1.NodeReady,
+						Status:             status,
+						LastTransitionTime: metav1.NewTime(time.Now().Add(-since).Truncate(time.Second)),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		gracePeriod       *metav1.Duration
+		node              *corev1.Node
+		existing          *clusterv1.Condition
+		wantNodeReadyTime bool
+		wantCondition     *clusterv1.Condition
+		wantRequeue       time.Duration
+	}{
+		{
+			name:              "no grace period, Node ready time is recorded",
+			node:              readyNode(corev1.ConditionTrue, time.Minute),
+			wantNodeReadyTime: true,
+		},
+		{
+			name:              "Node Ready for longer than the grace period",
+			gracePeriod:       &metav1.Duration{Duration: 5 * time.Minute},
+			node:              readyNode(corev1.ConditionTrue, 10*time.Minute),
+			wantNodeReadyTime: true,
+			wantCondition: &clusterv1.Condition{
+				Type:   clusterv1.NodeReadyCondition,
+				Status: corev1.ConditionTrue,
+			},
+		},
+		{
+			name:              "Node Ready for less than the grace period",
+			gracePeriod:       &metav1.Duration{Duration: 5 * time.Minute},
+			node:              readyNode(corev1.ConditionTrue, time.Minute),
+			wantNodeReadyTime: true,
+			wantCondition: &clusterv1.Condition{
+				Type:     clusterv1.NodeReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityInfo,
+				Reason:   clusterv1.WaitingForNodeReadinessGracePeriodReason,
+			},
+			wantRequeue: 4 * time.Minute,
+		},
+		{
+			name:        "Node not Ready",
+			gracePeriod: &metav1.Duration{Duration: 5 * time.Minute},
+			node:        readyNode(corev1.ConditionFalse, 10*time.Minute),
+			wantCondition: &clusterv1.Condition{
+				Type:     clusterv1.NodeReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityWarning,
+				Reason:   clusterv1.NodeNotReadyReason,
+			},
+			wantRequeue: 10 * time.Second,
+		},
+		{
+			name:        "Node not found, condition is left untouched",
+			gracePeriod: &metav1.Duration{Duration: 5 * time.Minute},
+			existing:    conditions.TrueCondition(clusterv1.NodeReadyCondition),
+			wantCondition: &clusterv1.Condition{
+				Type:   clusterv1.NodeReadyCondition,
+				Status: corev1.ConditionTrue,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default"},
+				Spec:       clusterv1.MachineSpec{NodeReadinessGracePeriod: tt.gracePeriod},
+				Status: clusterv1.MachineStatus{
+					NodeRef: &corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+				},
+			}
+			if tt.existing != nil {
+				conditions.Set(machine, tt.existing)
+			}
+
+			objs := []runtime.Object{}
+			if tt.node != nil {
+				objs = append(objs, tt.node)
+			}
+			r := &MachineReconciler{
+				Log:      log.Log,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			result := r.setNodeReadyCondition(context.Background(), fake.NewFakeClientWithScheme(scheme.Scheme, objs...), machine)
+			g.Expect(result.RequeueAfter).To(BeNumerically("~", tt.wantRequeue, 2*time.Second))
+
+			if tt.wantNodeReadyTime {
+				g.Expect(machine.Status.NodeReadyTime).NotTo(BeNil())
+				g.Expect(machine.Status.NodeReadyTime.Time).To(Equal(tt.node.Status.Conditions[0].LastTransitionTime.Time))
+			} else if tt.node != nil {
+				g.Expect(machine.Status.NodeReadyTime).To(BeNil())
+			}
+
+			if tt.wantCondition == nil {
+				g.Expect(conditions.Has(machine, clusterv1.NodeReadyCondition)).To(BeFalse())
+				return
+			}
+			got := conditions.Get(machine, clusterv1.NodeReadyCondition)
+			g.Expect(got).NotTo(BeNil())
+			g.Expect(got.Status).To(Equal(tt.wantCondition.Status))
+			g.Expect(got.Severity).To(Equal(tt.wantCondition.Severity))
+			g.Expect(got.Reason).To(Equal(tt.wantCondition.Reason))
+		})
+	}
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -34,6 +36,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 )
 
@@ -42,6 +45,16 @@ var (
 )
 
 func (r *MachineReconciler) reconcilePhase(_ context.Context, m *clusterv1.Machine) {
+	// Record the time bootstrap and infrastructure first became ready.
+	m.Status.BootstrapReadyTime = readyTime(m.Status.BootstrapReady, m.Status.BootstrapReadyTime)
+	m.Status.InfrastructureReadyTime = readyTime(m.Status.InfrastructureReady, m.Status.InfrastructureReadyTime)
+
+	// Report what the machine is waiting for, clearing the conditions once it's not waiting anymore.
+	conditions.SetTransitional(m, clusterv1.BootstrapReadyCondition, m.Status.BootstrapReady,
+		clusterv1.WaitingForBootstrapDataReason, "Waiting for the bootstrap provider to generate the bootstrap data")
+	conditions.SetTransitional(m, clusterv1.InfrastructureReadyCondition, m.Status.InfrastructureReady,
+		clusterv1.WaitingForInfrastructureReason, "Waiting for the infrastructure provider to report the infrastructure as ready")
+
 	// Set the phase to "pending" if nil.
 	if m.Status.Phase == "" {
 		m.Status.SetTypedPhase(clusterv1.MachinePhasePending)
@@ -57,8 +70,10 @@ func (r *MachineReconciler) reconcilePhase(_ context.Context, m *clusterv1.Machi
 		m.Status.SetTypedPhase(clusterv1.MachinePhaseProvisioned)
 	}
 
-	// Set the phase to "running" if there is a NodeRef field and infrastructure is ready.
-	if m.Status.NodeRef != nil && m.Status.InfrastructureReady {
+	// Set the phase to "running" if there is a NodeRef field, infrastructure is ready and
+	// the Node has been Ready for the readiness grace period, if any.
+	if m.Status.NodeRef != nil && m.Status.InfrastructureReady &&
+		(m.Spec.NodeReadinessGracePeriod == nil || conditions.IsTrue(m, clusterv1.NodeReadyCondition)) {
 		m.Status.SetTypedPhase(clusterv1.MachinePhaseRunning)
 	}
 
@@ -73,6 +88,20 @@ func (r *MachineReconciler) reconcilePhase(_ context.Context, m *clusterv1.Machi
 	}
 }
 
+// readyTime returns the time a ready flag first became true: the current time if ready was just set,
+// the given time if it was already recorded, and nil if the flag went back to false.
+func readyTime(ready bool, current *metav1.Time) *metav1.Time {
+	switch {
+	case !ready:
+		return nil
+	case current == nil:
+		now := metav1.Now()
+		return &now
+	default:
+		return current
+	}
+}
+
 // reconcileExternal handles generic unstructured objects referenced by a Machine.
 func (r *MachineReconciler) reconcileExternal(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine, ref *corev1.ObjectReference) (external.ReconcileOutput, error) {
 	logger := r.Log.WithValues("machine", m.Name, "namespace", m.Namespace)
@@ -84,8 +113,12 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, cluster *clus
 				"could not find %v %q for Machine %q in namespace %q, requeuing",
 				ref.GroupVersionKind(), ref.Name, m.Name, m.Namespace)
 		}
+		if isProviderCRDNotInstalled(err) {
+			return external.ReconcileOutput{}, markProviderCRDNotInstalled(r.recorder, m, ref, r.ProviderCRDRequeueAfter)
+		}
 		return external.ReconcileOutput{}, err
 	}
+	markProviderCRDInstalled(m, ref)
 
 	// if external ref is paused, return error.
 	if util.IsPaused(cluster, obj) {
@@ -251,6 +284,39 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		m.Spec.FailureDomain = pointer.StringPtr(failureDomain)
 	}
 
+	// Mirror the region, zone and instance type reported by the infrastructure provider, if any, onto the Machine labels.
+	r.reconcileInfrastructureLabels(m, infraConfig)
+
 	m.Spec.ProviderID = pointer.StringPtr(providerID)
 	return nil
 }
+
+// reconcileInfrastructureLabels sets the region, zone and instance type labels on the Machine to the values reported
+// in the status of its infrastructure object. Labels are left untouched for providers that don't report them.
+func (r *MachineReconciler) reconcileInfrastructureLabels(m *clusterv1.Machine, infraConfig *unstructured.Unstructured) {
+	logger := r.Log.WithValues("machine", m.Name, "namespace", m.Namespace)
+
+	for label, field := range map[string]string{
+		clusterv1.MachineRegionLabelName:       "region",
+		clusterv1.MachineZoneLabelName:         "zone",
+		clusterv1.MachineInstanceTypeLabelName: "instanceType",
+	} {
+		value, found, err := unstructured.NestedString(infraConfig.Object, "status", field)
+		if err != nil {
+			logger.V(4).Info("Ignoring field reported by infrastructure provider", "field", field, "error", err.Error())
+			continue
+		}
+		if !found || value == "" || m.Labels[label] == value {
+			continue
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			logger.Info("Ignoring invalid label value reported by infrastructure provider",
+				"field", field, "value", value, "reason", strings.Join(errs, "; "))
+			continue
+		}
+		if m.Labels == nil {
+			m.Labels = map[string]string{}
+		}
+		m.Labels[label] = value
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -151,6 +152,10 @@ var _ = Describe("Reconcile Machine Phases", func() {
 
 		r.reconcilePhase(context.Background(), machine)
 		Expect(machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhasePending))
+		Expect(machine.Status.BootstrapReadyTime).To(BeNil())
+		Expect(machine.Status.InfrastructureReadyTime).To(BeNil())
+		Expect(conditions.Get(machine, clusterv1.BootstrapReadyCondition).Reason).To(Equal(clusterv1.WaitingForBootstrapDataReason))
+		Expect(conditions.Get(machine, clusterv1.InfrastructureReadyCondition).Reason).To(Equal(clusterv1.WaitingForInfrastructureReason))
 	})
 
 	It("Should set `Provisioning` when bootstrap is ready", func() {
@@ -177,6 +182,8 @@ var _ = Describe("Reconcile Machine Phases", func() {
 
 		r.reconcilePhase(context.Background(), machine)
 		Expect(machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseProvisioning))
+		Expect(machine.Status.BootstrapReadyTime).NotTo(BeNil())
+		Expect(machine.Status.InfrastructureReadyTime).To(BeNil())
 	})
 
 	It("Should set `Running` when bootstrap and infra is ready", func() {
@@ -230,6 +237,9 @@ var _ = Describe("Reconcile Machine Phases", func() {
 
 		r.reconcilePhase(context.Background(), machine)
 		Expect(machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseRunning))
+		Expect(machine.Status.BootstrapReadyTime).NotTo(BeNil())
+		Expect(machine.Status.InfrastructureReadyTime).NotTo(BeNil())
+		expectNoWaitingConditions(machine)
 	})
 
 	It("Should set `Running` when bootstrap and infra is ready with no Status.Addresses", func() {
@@ -267,6 +277,7 @@ var _ = Describe("Reconcile Machine Phases", func() {
 
 		r.reconcilePhase(context.Background(), machine)
 		Expect(machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseRunning))
+		expectNoWaitingConditions(machine)
 	})
 
 	It("Should set `Running` when bootstrap, infra, and NodeRef is ready", func() {
@@ -315,6 +326,56 @@ var _ = Describe("Reconcile Machine Phases", func() {
 
 		r.reconcilePhase(context.Background(), machine)
 		Expect(machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseRunning))
+		expectNoWaitingConditions(machine)
+	})
+
+	It("Should clear the WaitingFor conditions when the Machine reaches `Running`", func() {
+		machine := defaultMachine.DeepCopy()
+		bootstrapConfig := defaultBootstrap.DeepCopy()
+		infraConfig := defaultInfra.DeepCopy()
+
+		// Set bootstrap ready.
+		err := unstructured.SetNestedField(bootstrapConfig.Object, true, "status", "ready")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = unstructured.SetNestedField(bootstrapConfig.Object, "secret-data", "status", "dataSecretName")
+		Expect(err).NotTo(HaveOccurred())
+
+		// Set NodeRef.
+		machine.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: "machine-test-node"}
+
+		r := &MachineReconciler{
+			Client: fake.NewFakeClientWithScheme(scheme.Scheme, defaultCluster, defaultKubeconfigSecret, machine, bootstrapConfig, infraConfig),
+			Log:    log.Log,
+			scheme: scheme.Scheme,
+		}
+
+		// The infrastructure isn't ready yet.
+		_, err = r.reconcile(context.Background(), defaultCluster, machine)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.reconcilePhase(context.Background(), machine)
+		Expect(conditions.IsFalse(machine, clusterv1.InfrastructureReadyCondition)).To(BeTrue())
+		Expect(conditions.Get(machine, clusterv1.InfrastructureReadyCondition).Reason).To(Equal(clusterv1.WaitingForInfrastructureReason))
+
+		// Set infra ready.
+		Expect(r.Client.Get(context.Background(), types.NamespacedName{Name: infraConfig.GetName(), Namespace: infraConfig.GetNamespace()}, infraConfig)).To(Succeed())
+
+		err = unstructured.SetNestedField(infraConfig.Object, "test://id-1", "spec", "providerID")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = unstructured.SetNestedField(infraConfig.Object, true, "status", "ready")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Client.Update(context.Background(), infraConfig)).To(Succeed())
+
+		_, err = r.reconcile(context.Background(), defaultCluster, machine)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.reconcilePhase(context.Background(), machine)
+		Expect(machine.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseRunning))
+		Expect(conditions.IsTrue(machine, clusterv1.InfrastructureReadyCondition)).To(BeTrue())
+		expectNoWaitingConditions(machine)
 	})
 
 	It("Should set `Provisioned` when there is a NodeRef but infra is not ready ", func() {
@@ -398,6 +459,31 @@ var _ = Describe("Reconcile Machine Phases", func() {
 	})
 })
 
+// expectNoWaitingConditions asserts that a machine has no leftover WaitingFor* False conditions.
+func expectNoWaitingConditions(m *clusterv1.Machine) {
+	for _, c := range m.GetConditions() {
+		if c.Status == corev1.ConditionFalse {
+			Expect(c.Reason).NotTo(HavePrefix("WaitingFor"), "condition %s", c.Type)
+		}
+	}
+}
+
+func TestReadyTime(t *testing.T) {
+	g := NewWithT(t)
+
+	// The time is recorded when the flag first becomes true.
+	first := readyTime(true, nil)
+	g.Expect(first).NotTo(BeNil())
+
+	// And it isn't overwritten on subsequent reconciles.
+	recorded := metav1.NewTime(time.Now().Add(-time.Hour))
+	g.Expect(readyTime(true, &recorded)).To(Equal(&recorded))
+
+	// It's cleared if the flag goes back to false.
+	g.Expect(readyTime(false, &recorded)).To(BeNil())
+	g.Expect(readyTime(false, nil)).To(BeNil())
+}
+
 func TestReconcileBootstrap(t *testing.T) {
 	defaultMachine := clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
@@ -762,6 +848,63 @@ func TestReconcileInfrastructure(t *testing.T) {
 				g.Expect(m.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseFailed))
 			},
 		},
+		{
+			name: "infrastructure config reports region, zone and instance type, expect labels",
+			machine: func() *clusterv1.Machine {
+				m := defaultMachine.DeepCopy()
+				m.Labels[clusterv1.MachineZoneLabelName] = "us-east-1a"
+				return m
+			}(),
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureConfig",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha3",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"providerID": "test://id-1",
+				},
+				"status": map[string]interface{}{
+					"ready":        true,
+					"region":       "us-east-1",
+					"zone":         "us-east-1b",
+					"instanceType": "m5.large",
+				},
+			},
+			expectError:   false,
+			expectChanged: true,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Labels).To(HaveKeyWithValue(clusterv1.MachineRegionLabelName, "us-east-1"))
+				g.Expect(m.Labels).To(HaveKeyWithValue(clusterv1.MachineZoneLabelName, "us-east-1b"))
+				g.Expect(m.Labels).To(HaveKeyWithValue(clusterv1.MachineInstanceTypeLabelName, "m5.large"))
+			},
+		},
+		{
+			name: "infrastructure config doesn't report region, zone and instance type, expect no labels",
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureConfig",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha3",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"providerID": "test://id-1",
+				},
+				"status": map[string]interface{}{
+					"ready": true,
+					"zone":  map[string]interface{}{"name": "us-east-1b"},
+				},
+			},
+			expectError:   false,
+			expectChanged: true,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Labels).NotTo(HaveKey(clusterv1.MachineRegionLabelName))
+				g.Expect(m.Labels).NotTo(HaveKey(clusterv1.MachineZoneLabelName))
+				g.Expect(m.Labels).NotTo(HaveKey(clusterv1.MachineInstanceTypeLabelName))
+			},
+		},
 		{
 			name: "infrastructure ref is paused",
 			infraConfig: map[string]interface{}{
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -29,18 +30,31 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conflict"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var (
 	// machineDeploymentKind contains the schema.GroupVersionKind for the MachineDeployment type.
 	machineDeploymentKind = clusterv1.GroupVersion.WithKind("MachineDeployment")
+
+	// defaultMachineDeploymentRequeueAfter is how long to wait before reconciling a MachineDeployment again
+	// while a rollout or scaling operation is in progress.
+	defaultMachineDeploymentRequeueAfter = 30 * time.Second
+
+	// minMachineDeploymentRequeueAfter and maxMachineDeploymentRequeueAfter are the bounds
+	// applied to the interval requested through the ReconcileIntervalAnnotation.
+	minMachineDeploymentRequeueAfter = 5 * time.Second
+	maxMachineDeploymentRequeueAfter = 10 * time.Minute
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
@@ -54,6 +68,13 @@ type MachineDeploymentReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
+	// ExcludedNamespaces is the list of namespaces whose objects are ignored by the controller.
+	ExcludedNamespaces []string
+
+	// ConflictRequeueAfter is how long to wait before reconciling an object again after an update
+	// conflict. If zero, the object is requeued with the controller's rate limiter.
+	ConflictRequeueAfter time.Duration
+
 	recorder record.EventRecorder
 }
 
@@ -66,7 +87,13 @@ func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, options
 			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.MachineSetToDeployments)},
 		).
 		WithOptions(options).
-		Complete(r)
+		WithEventFilter(predicates.ExcludeNamespaces(r.Log, r.ExcludedNamespaces)).
+		Complete(&conflict.Reconciler{
+			Controller:   "machinedeployment",
+			Reconciler:   r,
+			RequeueAfter: r.ConflictRequeueAfter,
+			Log:          r.Log,
+		})
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -76,6 +103,18 @@ func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, options
 	return nil
 }
 
+// DryRun returns a reconciler with the same settings, using the given client for all its reads and writes and
+// discarding its events. It's meant to be used with a dry-run client to preview the changes a reconcile would apply.
+func (r *MachineDeploymentReconciler) DryRun(c client.Client) reconcile.Reconciler {
+	return &MachineDeploymentReconciler{
+		Client:               c,
+		Log:                  r.Log.WithValues("dry-run", true),
+		ExcludedNamespaces:   r.ExcludedNamespaces,
+		ConflictRequeueAfter: r.ConflictRequeueAfter,
+		recorder:             &record.FakeRecorder{},
+	}
+}
+
 func (r *MachineDeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx := context.Background()
 	logger := r.Log.WithValues("machinedeployment", req.Name, "namespace", req.Namespace)
@@ -124,6 +163,10 @@ func (r *MachineDeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result
 
 	result, err := r.reconcile(ctx, cluster, deployment)
 	if err != nil {
+		// Conflicts are requeued quietly by the conflict.Reconciler wrapping this reconciler.
+		if conflict.IsConflict(err) {
+			return result, err
+		}
 		logger.Error(err, "Failed to reconcile MachineDeployment")
 		r.recorder.Eventf(deployment, corev1.EventTypeWarning, "ReconcileError", "%v", err)
 	}
@@ -167,17 +210,59 @@ func (r *MachineDeploymentReconciler) reconcile(_ context.Context, cluster *clus
 		return ctrl.Result{}, err
 	}
 
+	// Surface blocking conditions of the MachineSets on the MachineDeployment.
+	setMachineSetsReadyCondition(d, msList)
+
 	if d.Spec.Paused {
 		return ctrl.Result{}, r.sync(d, msList)
 	}
 
 	if d.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType {
-		return ctrl.Result{}, r.rolloutRolling(d, msList)
+		if err := r.rolloutRolling(d, msList); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Requeue while the rollout is still in progress.
+		if !mdutil.DeploymentComplete(d, &d.Status) {
+			return ctrl.Result{RequeueAfter: r.getRequeueAfter(d)}, nil
+		}
+		return ctrl.Result{}, nil
 	}
 
 	return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", d.Spec.Strategy.Type)
 }
 
+// getRequeueAfter returns how long to wait before reconciling a MachineDeployment with a rollout in progress.
+// The default interval can be overridden per MachineDeployment through the ReconcileIntervalAnnotation,
+// the value is clamped between minMachineDeploymentRequeueAfter and maxMachineDeploymentRequeueAfter.
+func (r *MachineDeploymentReconciler) getRequeueAfter(d *clusterv1.MachineDeployment) time.Duration {
+	logger := r.Log.WithValues("machinedeployment", d.Name, "namespace", d.Namespace)
+
+	value, ok := d.Annotations[clusterv1.ReconcileIntervalAnnotation]
+	if !ok {
+		return defaultMachineDeploymentRequeueAfter
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err == nil && interval <= 0 {
+		err = errors.New("interval must be greater than zero")
+	}
+	if err != nil {
+		logger.Error(err, "Ignoring invalid annotation", "annotation", clusterv1.ReconcileIntervalAnnotation, "value", value)
+		return defaultMachineDeploymentRequeueAfter
+	}
+
+	switch {
+	case interval < minMachineDeploymentRequeueAfter:
+		logger.V(4).Info("Reconcile interval is too short, using minimum", "value", value, "interval", minMachineDeploymentRequeueAfter)
+		return minMachineDeploymentRequeueAfter
+	case interval > maxMachineDeploymentRequeueAfter:
+		logger.V(4).Info("Reconcile interval is too long, using maximum", "value", value, "interval", maxMachineDeploymentRequeueAfter)
+		return maxMachineDeploymentRequeueAfter
+	}
+	return interval
+}
+
 // getMachineSetsForDeployment returns a list of MachineSets associated with a MachineDeployment.
 func (r *MachineDeploymentReconciler) getMachineSetsForDeployment(d *clusterv1.MachineDeployment) ([]*clusterv1.MachineSet, error) {
 	logger := r.Log.WithValues("machinedeployemnt", d.Name, "namespace", d.Namespace)
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -724,3 +725,60 @@ func TestGetMachineSetsForDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineDeploymentGetRequeueAfter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expected    time.Duration
+	}{
+		{
+			name:     "should return the default interval without annotation",
+			expected: defaultMachineDeploymentRequeueAfter,
+		},
+		{
+			name:        "should return the interval set through the annotation",
+			annotations: map[string]string{clusterv1.ReconcileIntervalAnnotation: "10s"},
+			expected:    10 * time.Second,
+		},
+		{
+			name:        "should clamp the interval to the minimum",
+			annotations: map[string]string{clusterv1.ReconcileIntervalAnnotation: "100ms"},
+			expected:    minMachineDeploymentRequeueAfter,
+		},
+		{
+			name:        "should clamp the interval to the maximum",
+			annotations: map[string]string{clusterv1.ReconcileIntervalAnnotation: "24h"},
+			expected:    maxMachineDeploymentRequeueAfter,
+		},
+		{
+			name:        "should ignore an invalid duration",
+			annotations: map[string]string{clusterv1.ReconcileIntervalAnnotation: "fast"},
+			expected:    defaultMachineDeploymentRequeueAfter,
+		},
+		{
+			name:        "should ignore a negative duration",
+			annotations: map[string]string{clusterv1.ReconcileIntervalAnnotation: "-1m"},
+			expected:    defaultMachineDeploymentRequeueAfter,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineDeploymentReconciler{
+				Log: log.Log,
+			}
+			md := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "md",
+					Namespace:   "default",
+					Annotations: tc.annotations,
+				},
+			}
+
+			g.Expect(r.getRequeueAfter(md)).To(Equal(tc.expected))
+		})
+	}
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package controllers
 
 import (
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/integer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 // rolloutRolling implements the logic for rolling a new machine set.
@@ -41,6 +45,8 @@ func (r *MachineDeploymentReconciler) rolloutRolling(d *clusterv1.MachineDeploym
 
 	allMSs := append(oldMSs, newMS)
 
+	r.recordRolloutStarted(d, newMS, oldMSs)
+
 	// Scale up, if we can.
 	if err := r.reconcileNewMachineSet(allMSs, newMS, d); err != nil {
 		return err
@@ -59,6 +65,8 @@ func (r *MachineDeploymentReconciler) rolloutRolling(d *clusterv1.MachineDeploym
 		return err
 	}
 
+	r.recordRolloutProgress(d, newMS, oldMSs)
+
 	if mdutil.DeploymentComplete(d, &d.Status) {
 		if err := r.cleanupDeployment(oldMSs, d); err != nil {
 			return err
@@ -109,6 +117,11 @@ func (r *MachineDeploymentReconciler) reconcileOldMachineSets(allMSs []*clusterv
 			newMS.Namespace, newMS.Name)
 	}
 
+	// Both the cleanup of unhealthy replicas and the scale down of old machine sets draw from the same
+	// budget, so together they never bring the number of ready machines below spec.strategy.minHealthyReplicas.
+	floor := newHealthyReplicaFloor(deployment, allMSs)
+	defer setMinHealthyReplicasCondition(deployment, floor)
+
 	oldMachinesCount := mdutil.GetReplicaCountForMachineSets(oldMSs)
 	if oldMachinesCount == 0 {
 		// Can't scale down further
@@ -159,7 +172,7 @@ func (r *MachineDeploymentReconciler) reconcileOldMachineSets(allMSs []*clusterv
 
 	// Clean up unhealthy replicas first, otherwise unhealthy replicas will block deployment
 	// and cause timeout. See https://github.com/kubernetes/kubernetes/issues/16737
-	oldMSs, cleanupCount, err := r.cleanupUnhealthyReplicas(oldMSs, deployment, maxScaledDown)
+	oldMSs, cleanupCount, err := r.cleanupUnhealthyReplicas(oldMSs, deployment, maxScaledDown, floor)
 	if err != nil {
 		return nil
 	}
@@ -169,17 +182,21 @@ func (r *MachineDeploymentReconciler) reconcileOldMachineSets(allMSs []*clusterv
 	// Scale down old machine sets, need check maxUnavailable to ensure we can scale down
 	allMSs = oldMSs
 	allMSs = append(allMSs, newMS)
-	scaledDownCount, err := r.scaleDownOldMachineSetsForRollingUpdate(allMSs, oldMSs, deployment)
+	scaledDownCount, err := r.scaleDownOldMachineSetsForRollingUpdate(allMSs, oldMSs, deployment, floor)
 	if err != nil {
 		return err
 	}
 
 	logger.V(4).Info("Scaled down old MachineSets of deployment", "count", scaledDownCount)
+	if floor != nil && floor.deferred > 0 {
+		logger.Info("Deferred scaling down old MachineSets to keep the minimum number of healthy replicas",
+			"count", floor.deferred, "minHealthyReplicas", floor.min)
+	}
 	return nil
 }
 
 // cleanupUnhealthyReplicas will scale down old machine sets with unhealthy replicas, so that all unhealthy replicas will be deleted.
-func (r *MachineDeploymentReconciler) cleanupUnhealthyReplicas(oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment, maxCleanupCount int32) ([]*clusterv1.MachineSet, int32, error) {
+func (r *MachineDeploymentReconciler) cleanupUnhealthyReplicas(oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment, maxCleanupCount int32, floor *healthyReplicaFloor) ([]*clusterv1.MachineSet, int32, error) {
 	logger := r.Log.WithValues("machinedeployment", deployment.Name, "namespace", deployment.Namespace)
 
 	sort.Sort(mdutil.MachineSetsByCreationTimestamp(oldMSs))
@@ -213,7 +230,10 @@ func (r *MachineDeploymentReconciler) cleanupUnhealthyReplicas(oldMSs []*cluster
 
 		remainingCleanupCount := maxCleanupCount - totalScaledDown
 		unhealthyCount := oldMSReplicas - oldMSAvailableReplicas
-		scaledDownCount := integer.Int32Min(remainingCleanupCount, unhealthyCount)
+		scaledDownCount := floor.limit(integer.Int32Min(remainingCleanupCount, unhealthyCount))
+		if scaledDownCount == 0 {
+			continue
+		}
 		newReplicasCount := oldMSReplicas - scaledDownCount
 
 		if newReplicasCount > oldMSReplicas {
@@ -232,7 +252,7 @@ func (r *MachineDeploymentReconciler) cleanupUnhealthyReplicas(oldMSs []*cluster
 
 // scaleDownOldMachineSetsForRollingUpdate scales down old machine sets when deployment strategy is "RollingUpdate".
 // Need check maxUnavailable to ensure availability
-func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(allMSs []*clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) (int32, error) {
+func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(allMSs []*clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment, floor *healthyReplicaFloor) (int32, error) {
 	logger := r.Log.WithValues("machinedeployment", deployment.Name, "namespace", deployment.Namespace)
 
 	if deployment.Spec.Replicas == nil {
@@ -273,7 +293,10 @@ func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(al
 		}
 
 		// Scale down.
-		scaleDownCount := integer.Int32Min(*(targetMS.Spec.Replicas), totalScaleDownCount-totalScaledDown)
+		scaleDownCount := floor.limit(integer.Int32Min(*(targetMS.Spec.Replicas), totalScaleDownCount-totalScaledDown))
+		if scaleDownCount == 0 {
+			continue
+		}
 		newReplicasCount := *(targetMS.Spec.Replicas) - scaleDownCount
 		if newReplicasCount > *(targetMS.Spec.Replicas) {
 			return totalScaledDown, errors.Errorf("when scaling down old MS, got invalid request to scale down %s/%s %d -> %d", targetMS.Namespace, targetMS.Name, *(targetMS.Spec.Replicas), newReplicasCount)
@@ -288,3 +311,147 @@ func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(al
 
 	return totalScaledDown, nil
 }
+
+// healthyReplicaFloor keeps track of how many machines a MachineDeployment can still remove
+// without going below spec.strategy.minHealthyReplicas. A nil floor doesn't limit anything.
+type healthyReplicaFloor struct {
+	// min is the minimum number of ready machines to keep.
+	min int32
+	// ready is the number of ready machines across all the machine sets of the deployment.
+	ready int32
+	// headroom is the number of machines that can still be removed.
+	headroom int32
+	// deferred is the number of replicas that were not scaled down to protect the floor.
+	deferred int32
+}
+
+// newHealthyReplicaFloor returns the floor for the given deployment, or nil if spec.strategy.minHealthyReplicas is not set.
+func newHealthyReplicaFloor(deployment *clusterv1.MachineDeployment, allMSs []*clusterv1.MachineSet) *healthyReplicaFloor {
+	if deployment.Spec.Strategy == nil || deployment.Spec.Strategy.MinHealthyReplicas == nil {
+		return nil
+	}
+
+	f := &healthyReplicaFloor{
+		min:   *deployment.Spec.Strategy.MinHealthyReplicas,
+		ready: mdutil.GetReadyReplicaCountForMachineSets(allMSs),
+	}
+	f.headroom = integer.Int32Max(0, f.ready-f.min)
+	return f
+}
+
+// limit returns how many of the wanted replicas a machine set can be scaled down by without going below the floor.
+// The delete policies of machine sets don't take the readiness of the Nodes into account, so any machine
+// removed may be a ready one and every replica scaled down is taken from the headroom.
+func (f *healthyReplicaFloor) limit(want int32) int32 {
+	if f == nil || want <= 0 {
+		return want
+	}
+
+	allowed := integer.Int32Min(want, f.headroom)
+	f.headroom -= allowed
+	f.deferred += want - allowed
+	return allowed
+}
+
+// setMinHealthyReplicasCondition reports on the MinHealthyReplicasSatisfied condition whether the deployment
+// had to defer scaling down machine sets to protect spec.strategy.minHealthyReplicas.
+// The condition is removed when no floor is set.
+func setMinHealthyReplicasCondition(deployment *clusterv1.MachineDeployment, floor *healthyReplicaFloor) {
+	if floor == nil {
+		conditions.Delete(deployment, clusterv1.MinHealthyReplicasSatisfiedCondition)
+		return
+	}
+
+	if floor.deferred > 0 {
+		conditions.MarkFalse(deployment, clusterv1.MinHealthyReplicasSatisfiedCondition, clusterv1.ScaleDownDeferredReason, clusterv1.ConditionSeverityWarning,
+			"Deferred scaling down %d replica(s): %d machine(s) ready, minHealthyReplicas is %d", floor.deferred, floor.ready, floor.min)
+		return
+	}
+	conditions.MarkTrue(deployment, clusterv1.MinHealthyReplicasSatisfiedCondition)
+}
+
+// recordRolloutStarted emits a RolloutStarted event when the deployment starts replacing the machines of
+// its old machine sets with the ones of its current revision, and starts tracing that rollout in annotations.
+// Scaling a deployment that has no old machines left isn't a rollout and isn't traced.
+func (r *MachineDeploymentReconciler) recordRolloutStarted(d *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet) {
+	revision := d.Annotations[mdutil.RevisionAnnotation]
+	if d.Annotations[mdutil.RolloutRevisionAnnotation] == revision {
+		return
+	}
+
+	oldReplicas := mdutil.GetReplicaCountForMachineSets(oldMSs)
+	if oldReplicas == 0 || mdutil.DeploymentComplete(d, &d.Status) {
+		return
+	}
+
+	d.Annotations[mdutil.RolloutRevisionAnnotation] = revision
+	d.Annotations[mdutil.RolloutStartTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	d.Annotations[mdutil.RolloutStepAnnotation] = formatRolloutStep(*(newMS.Spec.Replicas), oldReplicas)
+
+	r.recorder.Eventf(d, corev1.EventTypeNormal, "RolloutStarted", "Started rollout of revision %s to MachineSet %q, replacing %d old replica(s)",
+		revision, newMS.Name, oldReplicas)
+}
+
+// recordRolloutProgress emits a ScalingUp or ScalingDown event for each step of the traced rollout, and a
+// RolloutComplete event with its duration once the deployment is complete.
+// Steps are only reported when the new machine set grows or the old ones shrink past the last reported step,
+// so reconciling the same state again, even from a stale cache, doesn't emit duplicate events.
+func (r *MachineDeploymentReconciler) recordRolloutProgress(d *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet) {
+	revision := d.Annotations[mdutil.RevisionAnnotation]
+	if _, ok := d.Annotations[mdutil.RolloutRevisionAnnotation]; !ok {
+		return
+	}
+	if d.Annotations[mdutil.RolloutRevisionAnnotation] != revision {
+		// The traced rollout was superseded by a rollout that isn't traced yet.
+		clearRolloutAnnotations(d)
+		return
+	}
+
+	newReplicas := *(newMS.Spec.Replicas)
+	oldReplicas := mdutil.GetReplicaCountForMachineSets(oldMSs)
+	lastNew, lastOld, ok := parseRolloutStep(d.Annotations[mdutil.RolloutStepAnnotation])
+	if !ok {
+		lastNew, lastOld = newReplicas, oldReplicas
+	}
+
+	if newReplicas > lastNew {
+		r.recorder.Eventf(d, corev1.EventTypeNormal, "ScalingUp", "Scaled up MachineSet %q of revision %s from %d to %d replica(s)",
+			newMS.Name, revision, lastNew, newReplicas)
+		lastNew = newReplicas
+	}
+	if oldReplicas < lastOld {
+		r.recorder.Eventf(d, corev1.EventTypeNormal, "ScalingDown", "Scaled down old MachineSets from %d to %d replica(s) for revision %s",
+			lastOld, oldReplicas, revision)
+		lastOld = oldReplicas
+	}
+	d.Annotations[mdutil.RolloutStepAnnotation] = formatRolloutStep(lastNew, lastOld)
+
+	if !mdutil.DeploymentComplete(d, &d.Status) {
+		return
+	}
+
+	if started, err := time.Parse(time.RFC3339, d.Annotations[mdutil.RolloutStartTimeAnnotation]); err == nil {
+		r.recorder.Eventf(d, corev1.EventTypeNormal, "RolloutComplete", "Completed rollout of revision %s in %s",
+			revision, time.Since(started).Round(time.Second))
+	} else {
+		r.recorder.Eventf(d, corev1.EventTypeNormal, "RolloutComplete", "Completed rollout of revision %s", revision)
+	}
+	clearRolloutAnnotations(d)
+}
+
+func formatRolloutStep(newReplicas, oldReplicas int32) string {
+	return fmt.Sprintf("%d/%d", newReplicas, oldReplicas)
+}
+
+func parseRolloutStep(step string) (newReplicas, oldReplicas int32, ok bool) {
+	if _, err := fmt.Sscanf(step, "%d/%d", &newReplicas, &oldReplicas); err != nil {
+		return 0, 0, false
+	}
+	return newReplicas, oldReplicas, true
+}
+
+func clearRolloutAnnotations(d *clusterv1.MachineDeployment) {
+	delete(d.Annotations, mdutil.RolloutRevisionAnnotation)
+	delete(d.Annotations, mdutil.RolloutStartTimeAnnotation)
+	delete(d.Annotations, mdutil.RolloutStepAnnotation)
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestMachineDeploymentReconcileOldMachineSetsMinHealthyReplicas(t *testing.T) {
+	newMachineSet := func(name string, replicas, ready int32) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+			},
+			Status: clusterv1.MachineSetStatus{
+				Replicas:          replicas,
+				ReadyReplicas:     ready,
+				AvailableReplicas: ready,
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		minHealthyReplicas *int32
+		oldMS              *clusterv1.MachineSet
+		newMS              *clusterv1.MachineSet
+		wantOldReplicas    int32
+		wantCondition      *clusterv1.Condition
+	}{
+		{
+			name:            "scales down old machines when no floor is set",
+			oldMS:           newMachineSet("old", 3, 3),
+			newMS:           newMachineSet("new", 1, 1),
+			wantOldReplicas: 1,
+		},
+		{
+			name:               "scales down old machines when the floor is kept",
+			minHealthyReplicas: pointer.Int32Ptr(2),
+			oldMS:              newMachineSet("old", 3, 3),
+			newMS:              newMachineSet("new", 1, 1),
+			wantOldReplicas:    1,
+			wantCondition:      conditions.TrueCondition(clusterv1.MinHealthyReplicasSatisfiedCondition),
+		},
+		{
+			name:               "defers part of the scale down to keep the floor",
+			minHealthyReplicas: pointer.Int32Ptr(3),
+			oldMS:              newMachineSet("old", 3, 3),
+			newMS:              newMachineSet("new", 1, 1),
+			wantOldReplicas:    2,
+			wantCondition: conditions.FalseCondition(clusterv1.MinHealthyReplicasSatisfiedCondition, clusterv1.ScaleDownDeferredReason, clusterv1.ConditionSeverityWarning,
+				"Deferred scaling down 1 replica(s): 4 machine(s) ready, minHealthyReplicas is 3"),
+		},
+		{
+			name:               "defers the whole scale down when ready machines are at the floor",
+			minHealthyReplicas: pointer.Int32Ptr(4),
+			oldMS:              newMachineSet("old", 3, 3),
+			newMS:              newMachineSet("new", 1, 1),
+			wantOldReplicas:    3,
+			wantCondition: conditions.FalseCondition(clusterv1.MinHealthyReplicasSatisfiedCondition, clusterv1.ScaleDownDeferredReason, clusterv1.ConditionSeverityWarning,
+				"Deferred scaling down 2 replica(s): 4 machine(s) ready, minHealthyReplicas is 4"),
+		},
+		{
+			name:               "cleans up machines that aren't ready within the headroom",
+			minHealthyReplicas: pointer.Int32Ptr(1),
+			oldMS:              newMachineSet("old", 3, 1),
+			newMS:              newMachineSet("new", 1, 1),
+			wantOldReplicas:    2,
+			wantCondition: conditions.FalseCondition(clusterv1.MinHealthyReplicasSatisfiedCondition, clusterv1.ScaleDownDeferredReason, clusterv1.ConditionSeverityWarning,
+				"Deferred scaling down 1 replica(s): 2 machine(s) ready, minHealthyReplicas is 1"),
+		},
+		{
+			// The machine set may delete a ready machine rather than one that isn't ready.
+			name:               "defers cleaning up machines that aren't ready when ready machines are at the floor",
+			minHealthyReplicas: pointer.Int32Ptr(2),
+			oldMS:              newMachineSet("old", 3, 1),
+			newMS:              newMachineSet("new", 1, 1),
+			wantOldReplicas:    3,
+			wantCondition: conditions.FalseCondition(clusterv1.MinHealthyReplicasSatisfiedCondition, clusterv1.ScaleDownDeferredReason, clusterv1.ConditionSeverityWarning,
+				"Deferred scaling down 2 replica(s): 2 machine(s) ready, minHealthyReplicas is 2"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			maxUnavailable := intstr.FromInt(1)
+			maxSurge := intstr.FromInt(1)
+			deployment := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "md",
+					Namespace: "default",
+				},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas: pointer.Int32Ptr(3),
+					Strategy: &clusterv1.MachineDeploymentStrategy{
+						Type: clusterv1.RollingUpdateMachineDeploymentStrategyType,
+						RollingUpdate: &clusterv1.MachineRollingUpdateDeployment{
+							MaxUnavailable: &maxUnavailable,
+							MaxSurge:       &maxSurge,
+						},
+						MinHealthyReplicas: tt.minHealthyReplicas,
+					},
+				},
+			}
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			r := &MachineDeploymentReconciler{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, tt.oldMS.DeepCopy(), tt.newMS.DeepCopy()),
+				Log:      log.Log,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			allMSs := []*clusterv1.MachineSet{tt.oldMS, tt.newMS}
+			err := r.reconcileOldMachineSets(allMSs, []*clusterv1.MachineSet{tt.oldMS}, tt.newMS, deployment)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			oldMS := &clusterv1.MachineSet{}
+			g.Expect(r.Client.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "old"}, oldMS)).To(Succeed())
+			g.Expect(*oldMS.Spec.Replicas).To(Equal(tt.wantOldReplicas))
+
+			got := conditions.Get(deployment, clusterv1.MinHealthyReplicasSatisfiedCondition)
+			if tt.wantCondition == nil {
+				g.Expect(got).To(BeNil())
+				return
+			}
+			g.Expect(got).NotTo(BeNil())
+			g.Expect(got.Status).To(Equal(tt.wantCondition.Status))
+			g.Expect(got.Reason).To(Equal(tt.wantCondition.Reason))
+			g.Expect(got.Severity).To(Equal(tt.wantCondition.Severity))
+			g.Expect(got.Message).To(Equal(tt.wantCondition.Message))
+		})
+	}
+}
+
+func TestMachineDeploymentRolloutTimeline(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineDeploymentReconciler{
+		Log:      log.Log,
+		recorder: recorder,
+	}
+
+	deployment := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "md",
+			Namespace:   "default",
+			Generation:  2,
+			Annotations: map[string]string{mdutil.RevisionAnnotation: "2"},
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			Replicas: pointer.Int32Ptr(2),
+		},
+		Status: clusterv1.MachineDeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			AvailableReplicas:  2,
+		},
+	}
+	newMS := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "new"},
+		Spec:       clusterv1.MachineSetSpec{Replicas: pointer.Int32Ptr(0)},
+	}
+	oldMSs := []*clusterv1.MachineSet{{
+		ObjectMeta: metav1.ObjectMeta{Name: "old"},
+		Spec:       clusterv1.MachineSetSpec{Replicas: pointer.Int32Ptr(2)},
+	}}
+
+	// reconcile records the rollout like rolloutRolling does, and returns the events emitted.
+	reconcile := func(newReplicas, oldReplicas int32) []string {
+		r.recordRolloutStarted(deployment, newMS, oldMSs)
+		*newMS.Spec.Replicas = newReplicas
+		*oldMSs[0].Spec.Replicas = oldReplicas
+		deployment.Status.UpdatedReplicas = newReplicas
+		r.recordRolloutProgress(deployment, newMS, oldMSs)
+
+		var events []string
+		for {
+			select {
+			case e := <-recorder.Events:
+				events = append(events, e)
+			default:
+				return events
+			}
+		}
+	}
+
+	g.Expect(reconcile(1, 2)).To(Equal([]string{
+		`Normal RolloutStarted Started rollout of revision 2 to MachineSet "new", replacing 2 old replica(s)`,
+		`Normal ScalingUp Scaled up MachineSet "new" of revision 2 from 0 to 1 replica(s)`,
+	}))
+	g.Expect(deployment.Annotations).To(HaveKeyWithValue(mdutil.RolloutRevisionAnnotation, "2"))
+
+	// Reconciling the same state again doesn't emit any event.
+	g.Expect(reconcile(1, 2)).To(BeEmpty())
+
+	g.Expect(reconcile(1, 1)).To(Equal([]string{
+		`Normal ScalingDown Scaled down old MachineSets from 2 to 1 replica(s) for revision 2`,
+	}))
+
+	// A stale view of the machine sets doesn't report the same steps again.
+	g.Expect(reconcile(0, 2)).To(BeEmpty())
+	g.Expect(reconcile(1, 1)).To(BeEmpty())
+
+	deployment.Annotations[mdutil.RolloutStartTimeAnnotation] = time.Now().Add(-90 * time.Second).UTC().Format(time.RFC3339Nano)
+	g.Expect(reconcile(2, 0)).To(Equal([]string{
+		`Normal ScalingUp Scaled up MachineSet "new" of revision 2 from 1 to 2 replica(s)`,
+		`Normal ScalingDown Scaled down old MachineSets from 1 to 0 replica(s) for revision 2`,
+		`Normal RolloutComplete Completed rollout of revision 2 in 1m30s`,
+	}))
+	g.Expect(deployment.Annotations).NotTo(HaveKey(mdutil.RolloutRevisionAnnotation))
+	g.Expect(deployment.Annotations).NotTo(HaveKey(mdutil.RolloutStartTimeAnnotation))
+	g.Expect(deployment.Annotations).NotTo(HaveKey(mdutil.RolloutStepAnnotation))
+
+	// Once complete, scaling the deployment isn't traced as a rollout.
+	deployment.Spec.Replicas = pointer.Int32Ptr(3)
+	g.Expect(reconcile(3, 0)).To(BeEmpty())
+}
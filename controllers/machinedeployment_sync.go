@@ -32,6 +32,7 @@ import (
 	"k8s.io/client-go/util/retry"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -109,10 +110,12 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 		}
 
 		// Set existing new machine set's annotation
+		oversized := mdutil.OversizedDeploymentAnnotations(d, msCopy)
 		annotationsUpdated := mdutil.SetNewMachineSetAnnotations(d, msCopy, newRevision, true, logger)
 
 		minReadySecondsNeedsUpdate := msCopy.Spec.MinReadySeconds != *d.Spec.MinReadySeconds
 		if annotationsUpdated || minReadySecondsNeedsUpdate {
+			markAnnotationsSkipped(r.recorder, d, oversized, fmt.Sprintf("MachineSet %q", msCopy.Name))
 			msCopy.Spec.MinReadySeconds = *d.Spec.MinReadySeconds
 			return nil, patchHelper.Patch(context.Background(), msCopy)
 		}
@@ -121,6 +124,8 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 		err = r.updateMachineDeployment(d, func(innerDeployment *clusterv1.MachineDeployment) {
 			mdutil.SetDeploymentRevision(d, msCopy.Annotations[mdutil.RevisionAnnotation])
 		})
+		// The deployment is read again by the update, so the skipped annotations are only reported afterwards.
+		markAnnotationsSkipped(r.recorder, d, oversized, fmt.Sprintf("MachineSet %q", msCopy.Name))
 		return msCopy, err
 	}
 
@@ -175,6 +180,7 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 	*(newMS.Spec.Replicas) = newReplicasCount
 
 	// Set new machine set's annotation
+	oversized := mdutil.OversizedDeploymentAnnotations(d, &newMS)
 	mdutil.SetNewMachineSetAnnotations(d, &newMS, newRevision, false, logger)
 	// Create the new MachineSet. If it already exists, then we need to check for possible
 	// hash collisions. If there is any other error, we need to report it in the status of
@@ -218,6 +224,8 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 	err = r.updateMachineDeployment(d, func(innerDeployment *clusterv1.MachineDeployment) {
 		mdutil.SetDeploymentRevision(d, newRevision)
 	})
+	// The deployment is read again by the update, so the skipped annotations are only reported afterwards.
+	markAnnotationsSkipped(r.recorder, d, oversized, fmt.Sprintf("MachineSet %q", newMS.Name))
 
 	return createdMS, err
 }
@@ -369,6 +377,7 @@ func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet
 		ReadyReplicas:       mdutil.GetReadyReplicaCountForMachineSets(allMSs),
 		AvailableReplicas:   availableReplicas,
 		UnavailableReplicas: unavailableReplicas,
+		Conditions:          deployment.Status.Conditions,
 	}
 
 	if *deployment.Spec.Replicas == status.ReadyReplicas {
@@ -393,6 +402,55 @@ func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet
 	return status
 }
 
+// setMachineSetsReadyCondition mirrors the most severe False condition found on the MachineSets owned by
+// the deployment onto its MachineSetsReady condition, naming the MachineSet it comes from.
+// The condition goes back to True as soon as none of the MachineSets reports a False condition.
+func setMachineSetsReadyCondition(deployment *clusterv1.MachineDeployment, allMSs []*clusterv1.MachineSet) {
+	var (
+		blockedMS *clusterv1.MachineSet
+		blocking  *clusterv1.Condition
+	)
+	for _, ms := range allMSs {
+		if ms == nil {
+			continue
+		}
+		for i := range ms.Status.Conditions {
+			condition := &ms.Status.Conditions[i]
+			if condition.Status != corev1.ConditionFalse {
+				continue
+			}
+			// On equal severity prefer the MachineSet with the lowest name, so the mirrored
+			// condition doesn't flap between MachineSets across reconciles.
+			if blocking == nil ||
+				severityRank(condition.Severity) > severityRank(blocking.Severity) ||
+				(severityRank(condition.Severity) == severityRank(blocking.Severity) && ms.Name < blockedMS.Name) {
+				blockedMS, blocking = ms, condition
+			}
+		}
+	}
+
+	if blocking == nil {
+		conditions.MarkTrue(deployment, clusterv1.MachineSetsReadyCondition)
+		return
+	}
+	conditions.MarkFalse(deployment, clusterv1.MachineSetsReadyCondition, blocking.Reason, blocking.Severity,
+		"MachineSet %s: %s", blockedMS.Name, blocking.Message)
+}
+
+// severityRank orders condition severities, the higher the more severe.
+func severityRank(severity clusterv1.ConditionSeverity) int {
+	switch severity {
+	case clusterv1.ConditionSeverityError:
+		return 3
+	case clusterv1.ConditionSeverityWarning:
+		return 2
+	case clusterv1.ConditionSeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
 func (r *MachineDeploymentReconciler) scaleMachineSet(ms *clusterv1.MachineSet, newScale int32, deployment *clusterv1.MachineDeployment) error {
 	if ms.Spec.Replicas == nil {
 		return errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", ms.Name)
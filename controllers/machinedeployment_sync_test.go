@@ -17,12 +17,16 @@ limitations under the License.
 package controllers
 
 import (
+	"reflect"
 	"testing"
 
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestMachineDeploymentSyncStatus(t *testing.T) {
@@ -212,10 +216,101 @@ func TestMachineDeploymentSyncStatus(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			actualStatus := calculateStatus(test.machineSets, test.newMachineSet, test.deployment)
-			if actualStatus != test.expectedStatus {
+			if !reflect.DeepEqual(actualStatus, test.expectedStatus) {
 				t.Errorf("Expected %+v but got %+v", test.expectedStatus, actualStatus)
 			}
 		})
 
 	}
 }
+
+func TestMachineDeploymentSetMachineSetsReadyCondition(t *testing.T) {
+	newMachineSet := func(name string, conditions ...clusterv1.Condition) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     clusterv1.MachineSetStatus{Conditions: conditions},
+		}
+	}
+	notFound := clusterv1.Condition{
+		Type:     clusterv1.MachinesCreatedCondition,
+		Status:   corev1.ConditionFalse,
+		Reason:   clusterv1.TemplateNotFoundReason,
+		Severity: clusterv1.ConditionSeverityError,
+		Message:  "template not found",
+	}
+	warning := clusterv1.Condition{
+		Type:     "Other",
+		Status:   corev1.ConditionFalse,
+		Reason:   "SomethingOdd",
+		Severity: clusterv1.ConditionSeverityWarning,
+		Message:  "something odd",
+	}
+
+	var tests = map[string]struct {
+		machineSets     []*clusterv1.MachineSet
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		"no machine sets": {
+			expectedStatus: corev1.ConditionTrue,
+		},
+		"healthy machine sets": {
+			machineSets: []*clusterv1.MachineSet{
+				newMachineSet("ms-a", clusterv1.Condition{Type: clusterv1.MachinesCreatedCondition, Status: corev1.ConditionTrue}),
+				newMachineSet("ms-b"),
+			},
+			expectedStatus: corev1.ConditionTrue,
+		},
+		"mirrors the most severe condition": {
+			machineSets: []*clusterv1.MachineSet{
+				newMachineSet("ms-a", warning),
+				newMachineSet("ms-b", notFound),
+			},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  clusterv1.TemplateNotFoundReason,
+			expectedMessage: "MachineSet ms-b: template not found",
+		},
+		"prefers the lowest machine set name on equal severity": {
+			machineSets: []*clusterv1.MachineSet{
+				newMachineSet("ms-b", notFound),
+				newMachineSet("ms-a", notFound),
+			},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  clusterv1.TemplateNotFoundReason,
+			expectedMessage: "MachineSet ms-a: template not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			deployment := &clusterv1.MachineDeployment{}
+			setMachineSetsReadyCondition(deployment, test.machineSets)
+
+			condition := conditions.Get(deployment, clusterv1.MachineSetsReadyCondition)
+			g.Expect(condition).ToNot(BeNil())
+			g.Expect(condition.Status).To(Equal(test.expectedStatus))
+			g.Expect(condition.Reason).To(Equal(test.expectedReason))
+			g.Expect(condition.Message).To(Equal(test.expectedMessage))
+		})
+	}
+}
+
+func TestMachineDeploymentSetMachineSetsReadyConditionClears(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{Name: "ms"}}
+	conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.TemplateNotFoundReason, clusterv1.ConditionSeverityError, "template not found")
+
+	deployment := &clusterv1.MachineDeployment{}
+	setMachineSetsReadyCondition(deployment, []*clusterv1.MachineSet{ms})
+	g.Expect(conditions.IsFalse(deployment, clusterv1.MachineSetsReadyCondition)).To(BeTrue())
+
+	// Once the MachineSet recovers, the MachineDeployment condition is cleared.
+	conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
+	setMachineSetsReadyCondition(deployment, []*clusterv1.MachineSet{ms})
+	g.Expect(conditions.IsTrue(deployment, clusterv1.MachineSetsReadyCondition)).To(BeTrue())
+	g.Expect(conditions.Get(deployment, clusterv1.MachineSetsReadyCondition).Message).To(BeEmpty())
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conflict"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -49,6 +51,10 @@ type MachineHealthCheckReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
+	// ConflictRequeueAfter is how long to wait before reconciling an object again after an update
+	// conflict. If zero, the object is requeued with the controller's rate limiter.
+	ConflictRequeueAfter time.Duration
+
 	controller controller.Controller
 	recorder   record.EventRecorder
 }
@@ -61,7 +67,12 @@ func (r *MachineHealthCheckReconciler) SetupWithManager(mgr ctrl.Manager, option
 			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.clusterToMachineHealthCheck)},
 		).
 		WithOptions(options).
-		Build(r)
+		Build(&conflict.Reconciler{
+			Controller:   "machinehealthcheck",
+			Reconciler:   r,
+			RequeueAfter: r.ConflictRequeueAfter,
+			Log:          r.Log,
+		})
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -133,6 +144,10 @@ func (r *MachineHealthCheckReconciler) Reconcile(req ctrl.Request) (_ ctrl.Resul
 
 	result, err := r.reconcile(ctx, cluster, m)
 	if err != nil {
+		// Conflicts are requeued quietly by the conflict.Reconciler wrapping this reconciler.
+		if conflict.IsConflict(err) {
+			return result, err
+		}
 		logger.Error(err, "Failed to reconcile MachineHealthCheck")
 		r.recorder.Eventf(m, corev1.EventTypeWarning, "ReconcileError", "%v", err)
 
@@ -17,15 +17,36 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/conflict"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// maxListedInstances is the maximum number of Machines or instances named in the InstancesDeleted condition.
+	maxListedInstances = 10
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
@@ -33,12 +54,25 @@ import (
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools;machinepools/status,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch;delete
 
 // MachinePoolReconciler reconciles a MachineSet object
 type MachinePoolReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
+	// ExcludedNamespaces is the list of namespaces whose objects are ignored by the controller.
+	ExcludedNamespaces []string
+
+	// ConflictRequeueAfter is how long to wait before reconciling an object again after an update
+	// conflict. If zero, the object is requeued with the controller's rate limiter.
+	ConflictRequeueAfter time.Duration
+
+	// DisableFinalizer stops the controller from adding its finalizer to MachinePools, so deleting a
+	// MachinePool no longer cascades to its Machines and infrastructure instances. MachinePools that
+	// already have the finalizer are torn down even when it's disabled.
+	DisableFinalizer bool
+
 	config     *rest.Config
 	controller controller.Controller
 	recorder   record.EventRecorder
@@ -48,8 +82,15 @@ type MachinePoolReconciler struct {
 func (r *MachinePoolReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1.MachinePool{}).
+		Owns(&clusterv1.Machine{}).
 		WithOptions(options).
-		Build(r)
+		WithEventFilter(predicates.ExcludeNamespaces(r.Log, r.ExcludedNamespaces)).
+		Build(&conflict.Reconciler{
+			Controller:   "machinepool",
+			Reconciler:   r,
+			RequeueAfter: r.ConflictRequeueAfter,
+			Log:          r.Log,
+		})
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
@@ -62,8 +103,171 @@ func (r *MachinePoolReconciler) SetupWithManager(mgr ctrl.Manager, options contr
 	return nil
 }
 
-func (r *MachinePoolReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+func (r *MachinePoolReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx := context.Background()
+	logger := r.Log.WithValues("machinepool", req.Name, "namespace", req.Namespace)
+
+	mp := &clusterv1.MachinePool{}
+	if err := r.Client.Get(ctx, req.NamespacedName, mp); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return ctrl.Result{}, nil
+		}
+
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	cluster, err := util.GetClusterByName(ctx, r.Client, mp.Namespace, mp.Spec.ClusterName)
+	switch {
+	case apierrors.IsNotFound(err) && !mp.DeletionTimestamp.IsZero():
+		// The Cluster is already gone, so there's nothing left to pause the teardown of the MachinePool.
+	case err != nil:
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get Cluster %q for MachinePool %q in namespace %q",
+			mp.Spec.ClusterName, mp.Name, mp.Namespace)
+	case util.IsPaused(cluster, mp):
+		// Return early if the object or Cluster is paused.
+		logger.V(3).Info("reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper
+	patchHelper, err := patch.NewHelper(mp, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always attempt to patch the object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, mp); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// Handle deletion reconciliation loop.
+	if !mp.ObjectMeta.DeletionTimestamp.IsZero() {
+		if !util.Contains(mp.Finalizers, clusterv1.MachinePoolFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		return r.reconcileDelete(ctx, mp)
+	}
+
 	// TODO(juan-lee): Add machine pool implementation.
-	// TODO(vincepri): Add support for pause annotation and honoring Cluster.Spec.Paused.
+
+	// If the MachinePool doesn't have a finalizer, add one.
+	if !r.DisableFinalizer {
+		controllerutil.AddFinalizer(mp, clusterv1.MachinePoolFinalizer)
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileDelete tears down a MachinePool in order: it deletes the Machines the MachinePool controls, then its
+// infrastructure object, and waits for the infrastructure provider to empty spec.providerIDs before removing the finalizer.
+// The instances are considered gone once the infrastructure object is, even if spec.providerIDs wasn't emptied.
+// The InstancesDeleted condition lists the Machines or instances that are still being deleted.
+func (r *MachinePoolReconciler) reconcileDelete(ctx context.Context, mp *clusterv1.MachinePool) (ctrl.Result, error) {
+	logger := r.Log.WithValues("machinepool", mp.Name, "namespace", mp.Namespace)
+
+	mp.Status.SetTypedPhase(clusterv1.MachinePoolPhaseDeleting)
+
+	machines, err := r.getControlledMachines(ctx, mp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(machines) > 0 {
+		var errs []error
+		names := make([]string, 0, len(machines))
+		for _, m := range machines {
+			names = append(names, m.Name)
+			if !m.DeletionTimestamp.IsZero() {
+				continue
+			}
+
+			logger.Info("Deleting Machine", "machine", m.Name)
+			if err := r.Client.Delete(ctx, m); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "failed to delete Machine %q for MachinePool %q in namespace %q", m.Name, mp.Name, mp.Namespace))
+			}
+		}
+
+		conditions.MarkFalse(mp, clusterv1.InstancesDeletedCondition, clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for %d Machine(s) to be deleted: %s", len(names), summarizeNames(names))
+
+		// The MachinePool is reconciled again as its Machines go away.
+		return ctrl.Result{}, kerrors.NewAggregate(errs)
+	}
+
+	infraExists, err := r.deleteInfrastructure(ctx, mp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if infraExists && len(mp.Spec.ProviderIDs) > 0 {
+		logger.Info("Waiting for infrastructure instances to be deleted", "count", len(mp.Spec.ProviderIDs))
+		conditions.MarkFalse(mp, clusterv1.InstancesDeletedCondition, clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for %d instance(s) to be deleted: %s", len(mp.Spec.ProviderIDs), summarizeNames(mp.Spec.ProviderIDs))
+
+		// The infrastructure object isn't watched, so check again later in case it goes away
+		// without spec.providerIDs being updated.
+		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+	}
+
+	conditions.MarkTrue(mp, clusterv1.InstancesDeletedCondition)
+	controllerutil.RemoveFinalizer(mp, clusterv1.MachinePoolFinalizer)
 	return ctrl.Result{}, nil
 }
+
+// getControlledMachines returns the Machines controlled by the MachinePool.
+func (r *MachinePoolReconciler) getControlledMachines(ctx context.Context, mp *clusterv1.MachinePool) ([]*clusterv1.Machine, error) {
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machineList, client.InNamespace(mp.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list Machines for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+
+	var machines []*clusterv1.Machine
+	for i := range machineList.Items {
+		m := &machineList.Items[i]
+		if metav1.IsControlledBy(m, mp) {
+			machines = append(machines, m)
+		}
+	}
+	return machines, nil
+}
+
+// deleteInfrastructure issues a deletion request for the infrastructure object of the MachinePool, if it still exists.
+// It returns whether the infrastructure object still existed.
+func (r *MachinePoolReconciler) deleteInfrastructure(ctx context.Context, mp *clusterv1.MachinePool) (bool, error) {
+	ref := &mp.Spec.Template.Spec.InfrastructureRef
+	if ref.Name == "" {
+		return false, nil
+	}
+
+	obj, err := external.Get(ctx, r.Client, ref, mp.Namespace)
+	switch {
+	case apierrors.IsNotFound(errors.Cause(err)):
+		return false, nil
+	case err != nil:
+		return false, errors.Wrapf(err, "failed to get %s %q for MachinePool %q in namespace %q", ref.Kind, ref.Name, mp.Name, mp.Namespace)
+	case !obj.GetDeletionTimestamp().IsZero():
+		return true, nil
+	}
+
+	if err := r.Client.Delete(ctx, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to delete %s %q for MachinePool %q in namespace %q", ref.Kind, ref.Name, mp.Name, mp.Namespace)
+	}
+	return true, nil
+}
+
+// summarizeNames returns the sorted names as a comma separated list, truncated to maxListedInstances entries.
+func summarizeNames(names []string) string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	if len(sorted) <= maxListedInstances {
+		return strings.Join(sorted, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(sorted[:maxListedInstances], ", "), len(sorted)-maxListedInstances)
+}
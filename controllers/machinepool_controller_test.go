@@ -0,0 +1,319 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMachinePoolReconcileAddsFinalizer(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	tests := []struct {
+		name             string
+		disableFinalizer bool
+		paused           bool
+		wantFinalizers   []string
+	}{
+		{
+			name:           "adds the finalizer",
+			wantFinalizers: []string{clusterv1.MachinePoolFinalizer},
+		},
+		{
+			name:             "doesn't add the finalizer when it's disabled",
+			disableFinalizer: true,
+		},
+		{
+			name:   "doesn't add the finalizer when the Cluster is paused",
+			paused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Spec:       clusterv1.ClusterSpec{Paused: tt.paused},
+			}
+			mp := &clusterv1.MachinePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+				Spec:       clusterv1.MachinePoolSpec{ClusterName: "test-cluster"},
+			}
+			key := client.ObjectKey{Namespace: mp.Namespace, Name: mp.Name}
+			r := &MachinePoolReconciler{
+				Client:           fake.NewFakeClientWithScheme(scheme.Scheme, cluster, mp),
+				Log:              log.Log,
+				DisableFinalizer: tt.disableFinalizer,
+			}
+
+			_, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(r.Client.Get(context.Background(), key, mp)).To(Succeed())
+			g.Expect(mp.Finalizers).To(Equal(tt.wantFinalizers))
+		})
+	}
+}
+
+func TestMachinePoolReconcileDelete(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	dt := metav1.Now()
+	mp := &clusterv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pool",
+			Namespace:         "default",
+			UID:               "pool-uid",
+			Finalizers:        []string{clusterv1.MachinePoolFinalizer},
+			DeletionTimestamp: &dt,
+		},
+		Spec: clusterv1.MachinePoolSpec{
+			ClusterName: "test-cluster",
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "test-cluster",
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+						Kind:       "InfrastructureMachinePool",
+						Name:       "pool-infra",
+					},
+				},
+			},
+			ProviderIDs: []string{"aws:///us-east-1a/i-2", "aws:///us-east-1a/i-1"},
+		},
+	}
+
+	newMachine := func(name string, owner *metav1.OwnerReference) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: "test-cluster",
+				Bootstrap:   clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("data")},
+			},
+		}
+		if owner != nil {
+			m.OwnerReferences = []metav1.OwnerReference{*owner}
+		}
+		return m
+	}
+	poolRef := metav1.NewControllerRef(mp, clusterv1.GroupVersion.WithKind("MachinePool"))
+	owned := newMachine("pool-machine", poolRef)
+	unrelated := newMachine("unrelated-machine", nil)
+
+	infra := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha3",
+			"kind":       "InfrastructureMachinePool",
+			"metadata": map[string]interface{}{
+				"name":      "pool-infra",
+				"namespace": "default",
+			},
+		},
+	}
+
+	// The Cluster is already gone, which doesn't block the teardown.
+	key := client.ObjectKey{Namespace: mp.Namespace, Name: mp.Name}
+	r := &MachinePoolReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, mp, owned, unrelated, infra),
+		Log:    log.Log,
+	}
+
+	// The Machines controlled by the MachinePool are deleted first.
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(apierrors.IsNotFound(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pool-machine"}, &clusterv1.Machine{}))).To(BeTrue())
+	g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "unrelated-machine"}, &clusterv1.Machine{})).To(Succeed())
+	g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pool-infra"}, infra.DeepCopy())).To(Succeed())
+
+	g.Expect(r.Client.Get(ctx, key, mp)).To(Succeed())
+	g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhaseDeleting))
+	g.Expect(mp.Finalizers).To(ConsistOf(clusterv1.MachinePoolFinalizer))
+	condition := conditions.Get(mp, clusterv1.InstancesDeletedCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(clusterv1.DeletingReason))
+	g.Expect(condition.Message).To(Equal("Waiting for 1 Machine(s) to be deleted: pool-machine"))
+
+	// Once the Machines are gone, the infrastructure is deleted and the finalizer is held until
+	// the infrastructure provider removes its instances.
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(deleteRequeueAfter))
+
+	g.Expect(apierrors.IsNotFound(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pool-infra"}, infra.DeepCopy()))).To(BeTrue())
+
+	g.Expect(r.Client.Get(ctx, key, mp)).To(Succeed())
+	g.Expect(mp.Finalizers).To(ConsistOf(clusterv1.MachinePoolFinalizer))
+	condition = conditions.Get(mp, clusterv1.InstancesDeletedCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Message).To(Equal("Waiting for 2 instance(s) to be deleted: aws:///us-east-1a/i-1, aws:///us-east-1a/i-2"))
+
+	// The instances are considered gone with the infrastructure object, even if spec.providerIDs wasn't emptied.
+	_, err = r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(r.Client.Get(ctx, key, mp)).To(Succeed())
+	g.Expect(mp.Finalizers).To(BeEmpty())
+	g.Expect(conditions.IsTrue(mp, clusterv1.InstancesDeletedCondition)).To(BeTrue())
+}
+
+func TestMachinePoolReconcileCascadesDeletionByDefault(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+	}
+	mp := &clusterv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"},
+		Spec:       clusterv1.MachinePoolSpec{ClusterName: "test-cluster"},
+	}
+	owned := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pool-machine",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(mp, clusterv1.GroupVersion.WithKind("MachinePool"))},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			Bootstrap:   clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("data")},
+		},
+	}
+
+	key := client.ObjectKey{Namespace: mp.Namespace, Name: mp.Name}
+	r := &MachinePoolReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, cluster, mp, owned),
+		Log:    log.Log,
+	}
+
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(r.Client.Get(ctx, key, mp)).To(Succeed())
+	g.Expect(mp.Finalizers).To(ConsistOf(clusterv1.MachinePoolFinalizer))
+
+	// Deleting the MachinePool deletes the Machines it owns.
+	dt := metav1.Now()
+	mp.DeletionTimestamp = &dt
+	g.Expect(r.Client.Update(ctx, mp)).To(Succeed())
+
+	_, err = r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pool-machine"}, &clusterv1.Machine{}))).To(BeTrue())
+}
+
+func TestMachinePoolReconcileDeleteWaitsForInstances(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	dt := metav1.Now()
+	mp := &clusterv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pool",
+			Namespace:         "default",
+			Finalizers:        []string{clusterv1.MachinePoolFinalizer},
+			DeletionTimestamp: &dt,
+		},
+		Spec: clusterv1.MachinePoolSpec{
+			ClusterName: "test-cluster",
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "test-cluster",
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+						Kind:       "InfrastructureMachinePool",
+						Name:       "pool-infra",
+					},
+				},
+			},
+			ProviderIDs: []string{"aws:///us-east-1a/i-1"},
+		},
+	}
+	// The infrastructure object is held by the finalizer of the infrastructure provider.
+	infra := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha3",
+			"kind":       "InfrastructureMachinePool",
+			"metadata": map[string]interface{}{
+				"name":              "pool-infra",
+				"namespace":         "default",
+				"deletionTimestamp": dt.UTC().Format(time.RFC3339),
+				"finalizers":        []interface{}{"infrastructure.cluster.x-k8s.io"},
+			},
+		},
+	}
+
+	key := client.ObjectKey{Namespace: mp.Namespace, Name: mp.Name}
+	r := &MachinePoolReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, mp, infra),
+		Log:    log.Log,
+	}
+
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(deleteRequeueAfter))
+
+	g.Expect(r.Client.Get(ctx, key, mp)).To(Succeed())
+	g.Expect(mp.Finalizers).To(ConsistOf(clusterv1.MachinePoolFinalizer))
+	g.Expect(conditions.IsFalse(mp, clusterv1.InstancesDeletedCondition)).To(BeTrue())
+
+	// The finalizer is removed when the infrastructure provider removes the instances.
+	mp.Spec.ProviderIDs = nil
+	g.Expect(r.Client.Update(ctx, mp)).To(Succeed())
+
+	_, err = r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(r.Client.Get(ctx, key, mp)).To(Succeed())
+	g.Expect(mp.Finalizers).To(BeEmpty())
+	g.Expect(conditions.IsTrue(mp, clusterv1.InstancesDeletedCondition)).To(BeTrue())
+}
+
+func TestSummarizeNames(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(summarizeNames([]string{"b", "a"})).To(Equal("a, b"))
+	g.Expect(summarizeNames([]string{"k", "j", "i", "h", "g", "f", "e", "d", "c", "b", "a", "l"})).
+		To(Equal("a, b, c, d, e, f, g, h, i, j and 2 more"))
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -38,7 +39,10 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/conflict"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -58,6 +62,10 @@ var (
 	stateConfirmationInterval = 100 * time.Millisecond
 )
 
+// reservedMachineAnnotationsSize is the room, in bytes, kept free in the annotations of a new Machine
+// for the annotations set on it once it's created.
+const reservedMachineAnnotationsSize = 1 << 10
+
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;create;update;patch;delete
@@ -69,8 +77,18 @@ type MachineSetReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
+	// ExcludedNamespaces is the list of namespaces whose objects are ignored by the controller.
+	ExcludedNamespaces []string
+
+	// ConflictRequeueAfter is how long to wait before reconciling an object again after an update
+	// conflict. If zero, the object is requeued with the controller's rate limiter.
+	ConflictRequeueAfter time.Duration
+
 	recorder record.EventRecorder
 	scheme   *runtime.Scheme
+
+	// apiReader reads directly from the API server, bypassing the informer cache.
+	apiReader client.Reader
 }
 
 func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -82,7 +100,13 @@ func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options contro
 			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.MachineToMachineSets)},
 		).
 		WithOptions(options).
-		Complete(r)
+		WithEventFilter(predicates.ExcludeNamespaces(r.Log, r.ExcludedNamespaces)).
+		Complete(&conflict.Reconciler{
+			Controller:   "machineset",
+			Reconciler:   r,
+			RequeueAfter: r.ConflictRequeueAfter,
+			Log:          r.Log,
+		})
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -90,6 +114,7 @@ func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options contro
 
 	r.recorder = mgr.GetEventRecorderFor("machineset-controller")
 	r.scheme = mgr.GetScheme()
+	r.apiReader = mgr.GetAPIReader()
 	return nil
 }
 
@@ -126,7 +151,7 @@ func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 	}
 
 	result, err := r.reconcile(ctx, cluster, machineSet)
-	if err != nil {
+	if err != nil && !conflict.IsConflict(err) {
 		logger.Error(err, "Failed to reconcile MachineSet")
 		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "ReconcileError", "%v", err)
 	}
@@ -164,12 +189,12 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 
 	// Make sure to reconcile the external infrastructure reference.
 	if err := r.reconcileExternalReference(ctx, cluster, machineSet.Spec.Template.Spec.InfrastructureRef); err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.reconcileExternalReferenceError(ctx, machineSet, machineSet.Spec.Template.Spec.InfrastructureRef, err)
 	}
 	// Make sure to reconcile the external bootstrap reference, if any.
 	if machineSet.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
 		if err := r.reconcileExternalReference(ctx, cluster, *machineSet.Spec.Template.Spec.Bootstrap.ConfigRef); err != nil {
-			return ctrl.Result{}, err
+			return ctrl.Result{}, r.reconcileExternalReferenceError(ctx, machineSet, *machineSet.Spec.Template.Spec.Bootstrap.ConfigRef, err)
 		}
 	}
 
@@ -219,9 +244,11 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		filteredMachines = append(filteredMachines, machine)
 	}
 
-	syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
-
+	// Sync replicas on a copy of the MachineSet, so the conditions set while creating machines
+	// are detected as a change when patching the status below.
 	ms := machineSet.DeepCopy()
+	syncErr := r.syncReplicas(ctx, ms, filteredMachines)
+
 	newStatus, err := r.calculateStatus(ctx, cluster, ms, filteredMachines)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to calculate MachineSet's Status")
@@ -296,6 +323,23 @@ func (r *MachineSetReconciler) reconcileExternalReference(ctx context.Context, c
 	return nil
 }
 
+// reconcileExternalReferenceError records a MachinesCreated condition on the MachineSet when one of
+// its templates can't be found, so the failure is surfaced on the object and not only in the logs.
+// The original error is always returned.
+func (r *MachineSetReconciler) reconcileExternalReferenceError(ctx context.Context, ms *clusterv1.MachineSet, ref corev1.ObjectReference, err error) error {
+	if !apierrors.IsNotFound(errors.Cause(err)) {
+		return err
+	}
+
+	patch := client.MergeFrom(ms.DeepCopy())
+	conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.TemplateNotFoundReason, clusterv1.ConditionSeverityError,
+		"%s %q not found", ref.Kind, ref.Name)
+	if patchErr := r.Client.Status().Patch(ctx, ms, patch); patchErr != nil {
+		return kerrors.NewAggregate([]error{err, errors.Wrap(patchErr, "failed to patch MachineSet's Status")})
+	}
+	return err
+}
+
 // syncReplicas scales Machine resources up or down.
 func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
 	logger := r.Log.WithValues("machineset", ms.Name, "namespace", ms.Namespace)
@@ -303,6 +347,8 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		return errors.Errorf("the Replicas field in Spec for machineset %v is nil, this should not be allowed", ms.Name)
 	}
 
+	markAnnotationsSkipped(r.recorder, ms, oversizedMachineAnnotations(ms), "new Machines")
+
 	diff := len(machines) - int(*(ms.Spec.Replicas))
 
 	if diff < 0 {
@@ -332,6 +378,7 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 					Labels:      machine.Labels,
 				})
 				if err != nil {
+					markTemplateCloningFailed(ms, machine.Spec.Bootstrap.ConfigRef, err)
 					return errors.Wrapf(err, "failed to clone bootstrap configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 				}
 				machine.Spec.Bootstrap.ConfigRef = bootstrapRef
@@ -345,6 +392,7 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 				Labels:      machine.Labels,
 			})
 			if err != nil {
+				markTemplateCloningFailed(ms, &machine.Spec.InfrastructureRef, err)
 				return errors.Wrapf(err, "failed to clone infrastructure configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 			}
 			machine.Spec.InfrastructureRef = *infraRef
@@ -380,17 +428,47 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		}
 
 		if len(errstrings) > 0 {
+			conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.MachineCreationFailedReason, clusterv1.ConditionSeverityError,
+				"Failed to create %d of %d machines: %s", len(errstrings), diff, strings.Join(errstrings, "; "))
 			return errors.New(strings.Join(errstrings, "; "))
 		}
+		conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
 
 		return r.waitForMachineCreation(machineList)
 	} else if diff > 0 {
-		logger.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
+		// There are more machines than needed, clear any previous creation failure.
+		conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
 
 		deletePriorityFunc, err := getDeletePriorityFunc(ms)
 		if err != nil {
 			return err
 		}
+
+		if isReplicaOvershoot(ms) {
+			// The MachineSet hasn't been asked to scale down, the excess machines come from a race
+			// between adoption and creation. Confirm the overshoot against the API server, bypassing
+			// the cache, before deleting anything, so machines still being created or adopted aren't fought over.
+			machines, err = r.getControlledMachines(ctx, r.apiReader, ms)
+			if err != nil {
+				return err
+			}
+			diff = len(machines) - int(*(ms.Spec.Replicas))
+			if diff <= 0 {
+				logger.Info("Replica overshoot not confirmed by the API server, skipping deletion",
+					"need", *(ms.Spec.Replicas), "observed", len(machines))
+				return nil
+			}
+
+			// Unless a policy is configured, remove the machines that were added last.
+			if ms.Spec.DeletePolicy == "" {
+				deletePriorityFunc = newestDeletePriority
+			}
+			logger.Info("Replica overshoot detected", "need", *(ms.Spec.Replicas), "observed", len(machines), "deleting", diff)
+			r.recorder.Eventf(ms, corev1.EventTypeWarning, "ReplicaOvershoot",
+				"Observed %d machines for %d desired replicas, deleting %d excess machines", len(machines), *(ms.Spec.Replicas), diff)
+		} else {
+			logger.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
+		}
 		logger.Info("Found delete policy", "delete-policy", ms.Spec.DeletePolicy)
 		// Choose which Machines to delete.
 		machinesToDelete := getMachinesToDeletePrioritized(machines, diff, deletePriorityFunc)
@@ -425,12 +503,43 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		return r.waitForMachineDeletion(machinesToDelete)
 	}
 
+	// All the machines are there, clear any previous creation failure.
+	conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
 	return nil
 }
 
+// markTemplateCloningFailed sets the MachinesCreated condition to False, distinguishing a template
+// that doesn't exist from any other cloning error.
+func markTemplateCloningFailed(ms *clusterv1.MachineSet, ref *corev1.ObjectReference, err error) {
+	if apierrors.IsNotFound(errors.Cause(err)) {
+		conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.TemplateNotFoundReason, clusterv1.ConditionSeverityError,
+			"%s %q not found", ref.Kind, ref.Name)
+		return
+	}
+	conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.TemplateCloningFailedReason, clusterv1.ConditionSeverityError,
+		"Failed to clone %s %q: %v", ref.Kind, ref.Name, err)
+}
+
+// oversizedMachineAnnotations returns the keys of the template annotations of a MachineSet that aren't copied
+// to new Machines because they would grow their annotations over the size limit enforced by the API server.
+func oversizedMachineAnnotations(machineSet *clusterv1.MachineSet) []string {
+	return util.OversizedAnnotations(nil, machineSet.Spec.Template.Annotations, reservedMachineAnnotationsSize)
+}
+
 // getNewMachine creates a new Machine object. The name of the newly created resource is going
 // to be created by the API server, we set the generateName field.
+// Template annotations that would grow the Machine's annotations over the size limit are left out.
 func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *clusterv1.Machine {
+	annotations := machineSet.Spec.Template.Annotations
+	if oversized := oversizedMachineAnnotations(machineSet); len(oversized) > 0 {
+		annotations = make(map[string]string, len(machineSet.Spec.Template.Annotations))
+		for k, v := range machineSet.Spec.Template.Annotations {
+			if !util.Contains(oversized, k) {
+				annotations[k] = v
+			}
+		}
+	}
+
 	gv := clusterv1.GroupVersion
 	machine := &clusterv1.Machine{
 		TypeMeta: metav1.TypeMeta{
@@ -439,7 +548,7 @@ func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:      machineSet.Spec.Template.Labels,
-			Annotations: machineSet.Spec.Template.Annotations,
+			Annotations: annotations,
 		},
 		Spec: machineSet.Spec.Template.Spec,
 	}
@@ -462,6 +571,39 @@ func shouldExcludeMachine(machineSet *clusterv1.MachineSet, machine *clusterv1.M
 	return !machine.ObjectMeta.DeletionTimestamp.IsZero()
 }
 
+// isReplicaOvershoot returns true if the MachineSet has more machines than desired without having
+// been asked to scale down, i.e. the spec hasn't changed since the last observed status and the
+// last observed number of replicas didn't exceed the desired one.
+func isReplicaOvershoot(ms *clusterv1.MachineSet) bool {
+	return ms.Spec.Replicas != nil &&
+		ms.Generation == ms.Status.ObservedGeneration &&
+		ms.Status.Replicas <= *ms.Spec.Replicas
+}
+
+// getControlledMachines returns the Machines controlled by the MachineSet that aren't being deleted,
+// as read from the given reader.
+func (r *MachineSetReconciler) getControlledMachines(ctx context.Context, c client.Reader, ms *clusterv1.MachineSet) ([]*clusterv1.Machine, error) {
+	selectorMap, err := metav1.LabelSelectorAsMap(&ms.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert MachineSet %q label selector to a map", ms.Name)
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := c.List(ctx, machineList, client.InNamespace(ms.Namespace), client.MatchingLabels(selectorMap)); err != nil {
+		return nil, errors.Wrap(err, "failed to list machines")
+	}
+
+	machines := make([]*clusterv1.Machine, 0, len(machineList.Items))
+	for idx := range machineList.Items {
+		machine := &machineList.Items[idx]
+		if !metav1.IsControlledBy(machine, ms) || !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
 // adoptOrphan sets the MachineSet as a controller OwnerReference to the Machine.
 func (r *MachineSetReconciler) adoptOrphan(ctx context.Context, machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) error {
 	patch := client.MergeFrom(machine.DeepCopy())
@@ -643,7 +785,9 @@ func (r *MachineSetReconciler) calculateStatus(ctx context.Context, cluster *clu
 			continue
 		}
 
-		if noderefutil.IsNodeReady(node) {
+		// Machines defining a readiness grace period are only ready once their Node has been Ready for that long.
+		if noderefutil.IsNodeReady(node) &&
+			(machine.Spec.NodeReadinessGracePeriod == nil || conditions.IsTrue(machine, clusterv1.NodeReadyCondition)) {
 			readyReplicasCount++
 			if noderefutil.IsNodeAvailable(node, ms.Spec.MinReadySeconds, metav1.Now()) {
 				availableReplicasCount++
@@ -669,6 +813,7 @@ func (r *MachineSetReconciler) patchMachineSetStatus(ctx context.Context, ms *cl
 		ms.Status.FullyLabeledReplicas == newStatus.FullyLabeledReplicas &&
 		ms.Status.ReadyReplicas == newStatus.ReadyReplicas &&
 		ms.Status.AvailableReplicas == newStatus.AvailableReplicas &&
+		reflect.DeepEqual(ms.Status.Conditions, newStatus.Conditions) &&
 		ms.Generation == ms.Status.ObservedGeneration {
 		return ms, nil
 	}
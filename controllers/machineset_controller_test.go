@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,10 +28,12 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -682,6 +685,138 @@ func TestAdoptOrphan(t *testing.T) {
 	}
 }
 
+func TestIsReplicaOvershoot(t *testing.T) {
+	replicas := int32(2)
+	tests := []struct {
+		name     string
+		ms       *clusterv1.MachineSet
+		expected bool
+	}{
+		{
+			name: "spec unchanged and observed replicas within desired",
+			ms: &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       clusterv1.MachineSetSpec{Replicas: &replicas},
+				Status:     clusterv1.MachineSetStatus{ObservedGeneration: 2, Replicas: 2},
+			},
+			expected: true,
+		},
+		{
+			name: "spec changed since last observed status",
+			ms: &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       clusterv1.MachineSetSpec{Replicas: &replicas},
+				Status:     clusterv1.MachineSetStatus{ObservedGeneration: 2, Replicas: 2},
+			},
+			expected: false,
+		},
+		{
+			name: "scale down still in progress",
+			ms: &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       clusterv1.MachineSetSpec{Replicas: &replicas},
+				Status:     clusterv1.MachineSetStatus{ObservedGeneration: 2, Replicas: 4},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(isReplicaOvershoot(tt.ms)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestMachineSetSyncReplicasOvershoot(t *testing.T) {
+	ms := newMachineSet("overshoot", "test-cluster")
+	ms.UID = "overshoot-uid"
+	ms.Generation = 1
+	ms.Spec.Replicas = pointer.Int32Ptr(1)
+	ms.Status = clusterv1.MachineSetStatus{ObservedGeneration: 1, Replicas: 1}
+
+	newMachine := func(name string, age time.Duration) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         ms.Namespace,
+				Labels:            ms.Spec.Selector.MatchLabels,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(ms, machineSetKind)},
+			},
+		}
+	}
+	oldest := newMachine("oldest", time.Hour)
+	newest := newMachine("newest", time.Minute)
+	gone := newMachine("gone", time.Second)
+
+	tests := []struct {
+		name         string
+		cached       []*clusterv1.Machine
+		live         []*clusterv1.Machine
+		wantMachines []string
+		wantEvent    bool
+	}{
+		{
+			name:         "deletes only the excess machines confirmed by the API server",
+			cached:       []*clusterv1.Machine{oldest, newest, gone},
+			live:         []*clusterv1.Machine{oldest, newest},
+			wantMachines: []string{"oldest", "gone"},
+			wantEvent:    true,
+		},
+		{
+			name:         "skips deletion when the overshoot isn't confirmed by the API server",
+			cached:       []*clusterv1.Machine{oldest, gone},
+			live:         []*clusterv1.Machine{oldest},
+			wantMachines: []string{"oldest", "gone"},
+			wantEvent:    false,
+		},
+	}
+
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// The cache is stale and still lists machines the API server doesn't know about anymore.
+			cachedObjs := []runtime.Object{ms.DeepCopy()}
+			for _, m := range tt.cached {
+				cachedObjs = append(cachedObjs, m.DeepCopy())
+			}
+			liveObjs := []runtime.Object{ms.DeepCopy()}
+			for _, m := range tt.live {
+				liveObjs = append(liveObjs, m.DeepCopy())
+			}
+			recorder := record.NewFakeRecorder(32)
+			r := &MachineSetReconciler{
+				Client:    fake.NewFakeClientWithScheme(scheme.Scheme, cachedObjs...),
+				Log:       log.Log,
+				recorder:  recorder,
+				apiReader: fake.NewFakeClientWithScheme(scheme.Scheme, liveObjs...),
+			}
+
+			g.Expect(r.syncReplicas(context.Background(), ms.DeepCopy(), tt.cached)).To(Succeed())
+
+			machines := &clusterv1.MachineList{}
+			g.Expect(r.Client.List(context.Background(), machines, client.InNamespace(ms.Namespace))).To(Succeed())
+			names := []string{}
+			for _, m := range machines.Items {
+				names = append(names, m.Name)
+			}
+			g.Expect(names).To(ConsistOf(tt.wantMachines))
+
+			if tt.wantEvent {
+				g.Expect(recorder.Events).To(Receive(ContainSubstring("ReplicaOvershoot")))
+			} else {
+				g.Expect(recorder.Events).NotTo(Receive(ContainSubstring("ReplicaOvershoot")))
+			}
+		})
+	}
+}
+
 func TestHasMatchingLabels(t *testing.T) {
 	r := &MachineSetReconciler{
 		Log: klogr.New(),
@@ -814,3 +949,27 @@ func newMachineSet(name, cluster string) *clusterv1.MachineSet {
 		},
 	}
 }
+
+func TestMachineSetGetNewMachineSkipsOversizedAnnotations(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: clusterv1.MachineSetSpec{
+			ClusterName: "test-cluster",
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Annotations: map[string]string{
+						"small": "value",
+						"large": strings.Repeat("x", util.MaxAnnotationsSize),
+					},
+				},
+			},
+		},
+	}
+
+	r := &MachineSetReconciler{}
+	machine := r.getNewMachine(ms)
+	g.Expect(machine.Annotations).To(Equal(map[string]string{"small": "value"}))
+	g.Expect(ms.Spec.Template.Annotations).To(HaveKey("large"))
+}
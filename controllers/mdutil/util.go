@@ -34,6 +34,7 @@ import (
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/integer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
 )
 
 const (
@@ -51,6 +52,13 @@ const (
 	// is machinedeployment.spec.replicas + maxSurge. Used by the underlying machine sets to estimate their
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "machinedeployment.clusters.k8s.io/max-replicas"
+	// RolloutRevisionAnnotation is the revision whose rollout is being traced with events on a machine deployment.
+	RolloutRevisionAnnotation = "machinedeployment.clusters.k8s.io/rollout-revision"
+	// RolloutStartTimeAnnotation is the time the traced rollout started, in RFC3339 format.
+	RolloutStartTimeAnnotation = "machinedeployment.clusters.k8s.io/rollout-start-time"
+	// RolloutStepAnnotation is the last rollout step reported with an event, recorded as
+	// "<new machine set replicas>/<old machine sets replicas>". Used to avoid reporting the same step twice.
+	RolloutStepAnnotation = "machinedeployment.clusters.k8s.io/rollout-step"
 
 	// FailedMSCreateReason is added in a machine deployment when it cannot create a new machine set.
 	FailedMSCreateReason = "MachineSetCreateError"
@@ -157,6 +165,9 @@ var annotationsToSkip = map[string]bool{
 	RevisionHistoryAnnotation:      true,
 	DesiredReplicasAnnotation:      true,
 	MaxReplicasAnnotation:          true,
+	RolloutRevisionAnnotation:      true,
+	RolloutStartTimeAnnotation:     true,
+	RolloutStepAnnotation:          true,
 }
 
 // skipCopyAnnotation returns true if we should skip copying the annotation with the given annotation key
@@ -166,10 +177,28 @@ func skipCopyAnnotation(key string) bool {
 	return annotationsToSkip[key]
 }
 
+// reservedMachineSetAnnotationsSize is the room, in bytes, kept free in the annotations of a machine set
+// for the revision and replicas annotations set after copying the deployment's annotations.
+const reservedMachineSetAnnotationsSize = 1 << 10
+
+// OversizedDeploymentAnnotations returns the keys of the deployment's annotations that aren't copied to the
+// machine set because they would grow its annotations over the size limit enforced by the API server.
+func OversizedDeploymentAnnotations(deployment *clusterv1.MachineDeployment, ms *clusterv1.MachineSet) []string {
+	propagated := make(map[string]string, len(deployment.Annotations))
+	for k, v := range deployment.Annotations {
+		if !skipCopyAnnotation(k) {
+			propagated[k] = v
+		}
+	}
+	return util.OversizedAnnotations(ms.Annotations, propagated, reservedMachineSetAnnotationsSize)
+}
+
 // copyDeploymentAnnotationsToMachineSet copies deployment's annotations to machine set's annotations,
 // and returns true if machine set's annotation is changed.
-// Note that apply and revision annotations are not copied.
+// Note that apply and revision annotations are not copied, nor are the annotations that would grow
+// the machine set's annotations over the size limit.
 func copyDeploymentAnnotationsToMachineSet(deployment *clusterv1.MachineDeployment, ms *clusterv1.MachineSet) bool {
+	oversized := OversizedDeploymentAnnotations(deployment, ms)
 	msAnnotationsChanged := false
 	if ms.Annotations == nil {
 		ms.Annotations = make(map[string]string)
@@ -178,7 +207,7 @@ func copyDeploymentAnnotationsToMachineSet(deployment *clusterv1.MachineDeployme
 		// newMS revision is updated automatically in getNewMachineSet, and the deployment's revision number is then updated
 		// by copying its newMS revision number. We should not copy deployment's revision to its newMS, since the update of
 		// deployment revision number may fail (revision becomes stale) and the revision number in newMS is more reliable.
-		if skipCopyAnnotation(k) || ms.Annotations[k] == v {
+		if skipCopyAnnotation(k) || ms.Annotations[k] == v || util.Contains(oversized, k) {
 			continue
 		}
 		ms.Annotations[k] = v
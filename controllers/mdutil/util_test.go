@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -773,6 +774,28 @@ func TestAnnotationUtils(t *testing.T) {
 	//Tear Down
 }
 
+func TestSetNewMachineSetAnnotationsSkipsOversizedAnnotations(t *testing.T) {
+	tDeployment := generateDeployment("nginx")
+	tMS := generateMS(tDeployment)
+	tDeployment.Annotations["small"] = "value"
+	tDeployment.Annotations["large"] = strings.Repeat("x", 256*(1<<10))
+
+	if oversized := OversizedDeploymentAnnotations(&tDeployment, &tMS); !reflect.DeepEqual(oversized, []string{"large"}) {
+		t.Errorf("OversizedDeploymentAnnotations Expected=[large] Obtained=%v", oversized)
+	}
+
+	SetNewMachineSetAnnotations(&tDeployment, &tMS, "1", false, klogr.New())
+	if _, ok := tMS.Annotations["large"]; ok {
+		t.Errorf("SetNewMachineSetAnnotations copied the oversized annotation")
+	}
+	if tMS.Annotations["small"] != "value" {
+		t.Errorf("SetNewMachineSetAnnotations did not copy the small annotation")
+	}
+	if tMS.Annotations[RevisionAnnotation] != "1" {
+		t.Errorf("Revision Expected=1 Obtained=%s", tMS.Annotations[RevisionAnnotation])
+	}
+}
+
 func TestReplicasAnnotationsNeedUpdate(t *testing.T) {
 
 	desiredReplicas := fmt.Sprintf("%d", int32(10))
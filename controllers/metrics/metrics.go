@@ -93,6 +93,16 @@ var (
 		},
 		[]string{"machine", "namespace", "cluster"},
 	)
+
+	// ClusterMachineInventory is a metric counting the machines of a cluster
+	// by instance type and zone.
+	ClusterMachineInventory = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_cluster_machine_inventory",
+			Help: "Number of machines of the cluster by instance type and zone.",
+		},
+		[]string{"cluster", "namespace", "instance_type", "zone"},
+	)
 )
 
 func init() {
@@ -104,5 +114,6 @@ func init() {
 		MachineBootstrapReady,
 		MachineInfrastructureReady,
 		MachineNodeReady,
+		ClusterMachineInventory,
 	)
 }
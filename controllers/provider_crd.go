@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// defaultProviderCRDRequeueAfter is how long to wait before reading an object whose CRD isn't
+// installed again, when the reconciler doesn't configure it.
+const defaultProviderCRDRequeueAfter = time.Minute
+
+// isProviderCRDNotInstalled returns true if the error is caused by the API server not knowing the
+// kind of a referenced object.
+func isProviderCRDNotInstalled(err error) bool {
+	return meta.IsNoMatchError(errors.Cause(err))
+}
+
+// providerCRDNotInstalledMessage returns the ProviderCRDInstalled condition message for a reference.
+func providerCRDNotInstalledMessage(ref *corev1.ObjectReference) string {
+	return fmt.Sprintf("CRD for %v is not installed", ref.GroupVersionKind())
+}
+
+// markProviderCRDNotInstalled marks the ProviderCRDInstalled condition false for the kind of the given
+// reference, with an event, and returns an error requeuing the object after requeueAfter, or after
+// the default when it's zero.
+func markProviderCRDNotInstalled(recorder record.EventRecorder, obj conditions.Setter, ref *corev1.ObjectReference, requeueAfter time.Duration) error {
+	if requeueAfter <= 0 {
+		requeueAfter = defaultProviderCRDRequeueAfter
+	}
+
+	conditions.MarkFalseWithEvent(recorder, obj, clusterv1.ProviderCRDInstalledCondition, clusterv1.ProviderCRDNotInstalledReason,
+		clusterv1.ConditionSeverityWarning, "%s", providerCRDNotInstalledMessage(ref))
+
+	return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: requeueAfter},
+		"no CRD installed for %v %q, requeuing", ref.GroupVersionKind(), ref.Name)
+}
+
+// markProviderCRDInstalled marks the ProviderCRDInstalled condition true once the kind of the given
+// reference, previously reported as missing, can be read. The condition is left untouched if it
+// reports a different reference, which is still missing.
+func markProviderCRDInstalled(obj conditions.Setter, ref *corev1.ObjectReference) {
+	c := conditions.Get(obj, clusterv1.ProviderCRDInstalledCondition)
+	if c == nil || (c.Status == corev1.ConditionFalse && c.Message != providerCRDNotInstalledMessage(ref)) {
+		return
+	}
+	conditions.MarkTrue(obj, clusterv1.ProviderCRDInstalledCondition)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// noKindMatchClient is a client failing to read objects of the given kind as if its CRD wasn't installed.
+type noKindMatchClient struct {
+	client.Client
+	gvk schema.GroupVersionKind
+}
+
+func (c *noKindMatchClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if obj.GetObjectKind().GroupVersionKind() == c.gvk {
+		return &meta.NoKindMatchError{GroupKind: c.gvk.GroupKind(), SearchedVersions: []string{c.gvk.Version}}
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestMachineReconcileExternalProviderCRDNotInstalled(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	ref := &corev1.ObjectReference{
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+		Kind:       "InfrastructureMachine",
+		Name:       "infra-config1",
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-test", Namespace: "default"},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       cluster.Name,
+			InfrastructureRef: *ref,
+		},
+	}
+
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineReconciler{
+		Client: &noKindMatchClient{
+			Client: fake.NewFakeClientWithScheme(scheme.Scheme, cluster, machine),
+			gvk:    ref.GroupVersionKind(),
+		},
+		Log:                     log.Log,
+		ProviderCRDRequeueAfter: 5 * time.Minute,
+		scheme:                  scheme.Scheme,
+		recorder:                recorder,
+	}
+
+	// The first failed read reports the missing CRD and backs off.
+	_, err := r.reconcileExternal(context.Background(), cluster, machine, ref)
+	g.Expect(err).To(HaveOccurred())
+	requeueErr, ok := errors.Cause(err).(capierrors.HasRequeueAfterError)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(requeueErr.GetRequeueAfter()).To(Equal(5 * time.Minute))
+
+	c := conditions.Get(machine, clusterv1.ProviderCRDInstalledCondition)
+	g.Expect(c).NotTo(BeNil())
+	g.Expect(c.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(c.Reason).To(Equal(clusterv1.ProviderCRDNotInstalledReason))
+	g.Expect(c.Message).To(ContainSubstring("InfrastructureMachine"))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring(clusterv1.ProviderCRDNotInstalledReason)))
+
+	// Later failed reads don't emit the event again.
+	_, err = r.reconcileExternal(context.Background(), cluster, machine, ref)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(recorder.Events).NotTo(Receive())
+}
+
+func TestMarkProviderCRDInstalled(t *testing.T) {
+	bootstrapRef := &corev1.ObjectReference{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3", Kind: "BootstrapConfig", Name: "bootstrap-config1"}
+	infraRef := &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3", Kind: "InfrastructureMachine", Name: "infra-config1"}
+
+	t.Run("doesn't add the condition when no CRD was missing", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &clusterv1.Machine{}
+		markProviderCRDInstalled(machine, infraRef)
+		g.Expect(conditions.Has(machine, clusterv1.ProviderCRDInstalledCondition)).To(BeFalse())
+	})
+
+	t.Run("keeps reporting another reference's missing CRD", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &clusterv1.Machine{}
+		g.Expect(markProviderCRDNotInstalled(record.NewFakeRecorder(32), machine, infraRef, 0)).NotTo(Succeed())
+		markProviderCRDInstalled(machine, bootstrapRef)
+		g.Expect(conditions.IsFalse(machine, clusterv1.ProviderCRDInstalledCondition)).To(BeTrue())
+	})
+
+	t.Run("recovers once the CRD is installed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := &clusterv1.Machine{}
+		err := markProviderCRDNotInstalled(record.NewFakeRecorder(32), machine, infraRef, 0)
+		requeueErr, ok := errors.Cause(err).(capierrors.HasRequeueAfterError)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(requeueErr.GetRequeueAfter()).To(Equal(defaultProviderCRDRequeueAfter))
+
+		markProviderCRDInstalled(machine, infraRef)
+		g.Expect(conditions.IsTrue(machine, clusterv1.ProviderCRDInstalledCondition)).To(BeTrue())
+	})
+}
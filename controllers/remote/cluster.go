@@ -38,6 +38,11 @@ func NewClusterClient(ctx context.Context, c client.Client, cluster *clusterv1.C
 	if err != nil {
 		return nil, err
 	}
+	return newClient(restConfig, cluster, scheme)
+}
+
+// newClient returns a Client for interacting with a remote Cluster using the given REST configuration and scheme.
+func newClient(restConfig *restclient.Config, cluster *clusterv1.Cluster, scheme *runtime.Scheme) (client.Client, error) {
 	mapper, err := apiutil.NewDynamicRESTMapper(restConfig, apiutil.WithLazyDiscovery)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create a DynamicRESTMapper for Cluster %s/%s", cluster.Namespace, cluster.Name)
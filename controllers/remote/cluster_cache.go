@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterClientCache caches the clients of workload clusters, so they aren't created again every time a
+// controller needs one. A client is created again once the kubeconfig secret of its Cluster changes.
+// A ClusterClientCache must always be used with the same scheme. The zero value is ready to use, while a nil
+// ClusterClientCache creates a new client every time.
+type ClusterClientCache struct {
+	lock    sync.Mutex
+	clients map[types.NamespacedName]cachedClusterClient
+}
+
+type cachedClusterClient struct {
+	client client.Client
+
+	// kubeconfigVersion is the resource version of the kubeconfig secret the client was created from.
+	kubeconfigVersion string
+}
+
+var _ ClusterClientGetter = (&ClusterClientCache{}).Get
+
+// Get returns a client for the workload cluster of the given Cluster, creating it only if none was created from
+// the current kubeconfig secret of the Cluster. The secret is read with the given client, which should read from
+// the manager's cache.
+func (cc *ClusterClientCache) Get(ctx context.Context, c client.Client, cluster *clusterv1.Cluster, scheme *runtime.Scheme) (client.Client, error) {
+	if cc == nil {
+		return NewClusterClient(ctx, c, cluster, scheme)
+	}
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+
+	kubeconfigSecret, err := secret.Get(ctx, c, cluster, secret.Kubeconfig)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			cc.delete(key)
+		}
+		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	if cached, ok := cc.clients[key]; ok && cached.kubeconfigVersion == kubeconfigSecret.ResourceVersion {
+		return cached.client, nil
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfigSecret.Data[secret.KubeconfigDataName])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create REST configuration for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	clusterClient, err := newClient(restConfig, cluster, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	if cc.clients == nil {
+		cc.clients = map[types.NamespacedName]cachedClusterClient{}
+	}
+	cc.clients[key] = cachedClusterClient{
+		client:            clusterClient,
+		kubeconfigVersion: kubeconfigSecret.ResourceVersion,
+	}
+	return clusterClient, nil
+}
+
+func (cc *ClusterClientCache) delete(key types.NamespacedName) {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+	delete(cc.clients, key)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func TestClusterClientCache(t *testing.T) {
+	g := NewWithT(t)
+
+	testScheme := runtime.NewScheme()
+	g.Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+	ctx := context.Background()
+
+	c := fake.NewFakeClientWithScheme(testScheme, validSecret.DeepCopy(), invalidSecret.DeepCopy())
+	cache := &ClusterClientCache{}
+
+	first, err := cache.Get(ctx, c, clusterWithValidKubeConfig, testScheme)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(first).NotTo(BeNil())
+
+	// The client is reused while the kubeconfig secret doesn't change.
+	second, err := cache.Get(ctx, c, clusterWithValidKubeConfig, testScheme)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(second).To(BeIdenticalTo(first))
+
+	// A new client is created once the kubeconfig secret changes.
+	kubeconfigSecret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test1-kubeconfig"}, kubeconfigSecret)).To(Succeed())
+	kubeconfigSecret.Data[secret.KubeconfigDataName] = []byte(validKubeConfig + "\n")
+	g.Expect(c.Update(ctx, kubeconfigSecret)).To(Succeed())
+
+	third, err := cache.Get(ctx, c, clusterWithValidKubeConfig, testScheme)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(third).NotTo(BeIdenticalTo(first))
+
+	// The client is dropped once the kubeconfig secret is deleted.
+	g.Expect(c.Delete(ctx, kubeconfigSecret)).To(Succeed())
+	_, err = cache.Get(ctx, c, clusterWithValidKubeConfig, testScheme)
+	g.Expect(err).To(MatchError(ContainSubstring("not found")))
+	g.Expect(cache.clients).To(BeEmpty())
+
+	_, err = cache.Get(ctx, c, clusterWithInvalidKubeConfig, testScheme)
+	g.Expect(err).To(HaveOccurred())
+	_, err = cache.Get(ctx, c, clusterWithNoKubeConfig, testScheme)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -22,6 +22,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -52,6 +53,22 @@ func (r *KubeadmControlPlane) Default() {
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *KubeadmControlPlane) ValidateCreate() error {
+	return capiwebhook.Validate(GroupVersion.WithKind("KubeadmControlPlane"), capiwebhook.OperationCreate, r, r.validateCreate)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
+	return capiwebhook.Validate(GroupVersion.WithKind("KubeadmControlPlane"), capiwebhook.OperationUpdate, r, func() error {
+		return r.validateUpdate(old)
+	})
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *KubeadmControlPlane) ValidateDelete() error {
+	return nil
+}
+
+func (r *KubeadmControlPlane) validateCreate() error {
 	var allErrs field.ErrorList
 
 	if r.Spec.Replicas == nil {
@@ -112,8 +129,7 @@ func (r *KubeadmControlPlane) ValidateCreate() error {
 	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmControlPlane").GroupKind(), r.Name, allErrs)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
+func (r *KubeadmControlPlane) validateUpdate(old runtime.Object) error {
 	var allErrs field.ErrorList
 
 	oldKubeadmControlPlane := old.(*KubeadmControlPlane)
@@ -133,8 +149,3 @@ func (r *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 
 	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmControlPlane").GroupKind(), r.Name, allErrs)
 }
-
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *KubeadmControlPlane) ValidateDelete() error {
-	return nil
-}
@@ -32,6 +32,8 @@ import (
 	kubeadmbootstrapv1alpha3 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	kubeadmcontrolplanev1alpha3 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
 	kubeadmcontrolplanecontrollers "sigs.k8s.io/cluster-api/controlplane/kubeadm/controllers"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
+	webhookmetrics "sigs.k8s.io/cluster-api/util/webhook/metrics"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -84,7 +86,10 @@ func main() {
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
 	flag.IntVar(&webhookPort, "webhook-port", 0,
-		"Webhook Server port, disabled by default. When enabled, the manager will only work as webhook server, no reconcilers are installed.")
+		"Webhook Server port, disabled by default. When enabled, the manager will only work as webhook server, no reconcilers are installed. Webhook metrics are still served on the metrics address.")
+
+	flag.IntVar(&capiwebhook.RejectionLogVerbosity, "webhook-rejection-log-verbosity", 0,
+		"The log verbosity at which admission requests rejected by the validation webhooks are logged, along with the webhook name, the object and the field errors.")
 
 	flag.Parse()
 
@@ -142,6 +147,12 @@ func setupWebhooks(mgr ctrl.Manager) {
 		return
 	}
 
+	// Register the defaulting and validating webhooks first, recording the admission requests they handle.
+	// Setting up the webhooks below skips the paths already registered.
+	if err := webhookmetrics.RegisterWebhooks(mgr, &kubeadmcontrolplanev1alpha3.KubeadmControlPlane{}); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "KubeadmControlPlane")
+		os.Exit(1)
+	}
 	if err := (&kubeadmcontrolplanev1alpha3.KubeadmControlPlane{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "KubeadmControlPlane")
 		os.Exit(1)
@@ -20,9 +20,13 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
@@ -35,6 +39,11 @@ import (
 	clusterv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	clusterv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers"
+	"sigs.k8s.io/cluster-api/util/diagnostics"
+	"sigs.k8s.io/cluster-api/util/dryrun"
+	"sigs.k8s.io/cluster-api/util/pause"
+	capiwebhook "sigs.k8s.io/cluster-api/util/webhook"
+	webhookmetrics "sigs.k8s.io/cluster-api/util/webhook/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	// +kubebuilder:scaffold:imports
 )
@@ -47,6 +56,7 @@ var (
 	metricsAddr                  string
 	enableLeaderElection         bool
 	watchNamespace               string
+	excludedNamespaces           string
 	profilerAddress              string
 	clusterConcurrency           int
 	machineConcurrency           int
@@ -56,6 +66,11 @@ var (
 	syncPeriod                   time.Duration
 	webhookPort                  int
 	healthAddr                   string
+	diagnosticsAddr              string
+	diagnosticsTokenFile         string
+	conflictRequeueAfter         time.Duration
+	providerCRDRequeueAfter      time.Duration
+	disableMachinePoolFinalizer  bool
 )
 
 func init() {
@@ -77,6 +92,9 @@ func main() {
 	flag.StringVar(&watchNamespace, "namespace", "",
 		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
 
+	flag.StringVar(&excludedNamespaces, "excluded-namespaces", "",
+		"Comma-separated list of namespaces whose cluster-api objects are ignored by the controllers. Objects in all the other watched namespaces are reconciled.")
+
 	flag.StringVar(&profilerAddress, "profiler-address", "",
 		"Bind address to expose the pprof profiler (e.g. localhost:6060)")
 
@@ -99,11 +117,29 @@ func main() {
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
 	flag.IntVar(&webhookPort, "webhook-port", 0,
-		"Webhook Server port, disabled by default. When enabled, the manager will only work as webhook server, no reconcilers are installed.")
+		"Webhook Server port, disabled by default. When enabled, the manager will only work as webhook server, no reconcilers are installed. Webhook metrics are still served on the metrics address.")
+
+	flag.IntVar(&capiwebhook.RejectionLogVerbosity, "webhook-rejection-log-verbosity", 0,
+		"The log verbosity at which admission requests rejected by the validation webhooks are logged, along with the webhook name, the object and the field errors.")
 
 	flag.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
 
+	flag.StringVar(&diagnosticsAddr, "diagnostics-addr", ":9441",
+		"The address the diagnostic endpoints bind to, if they are enabled with --diagnostics-token-file.")
+
+	flag.StringVar(&diagnosticsTokenFile, "diagnostics-token-file", "",
+		"Path to a file containing the bearer token protecting the diagnostic endpoints. If unspecified, the diagnostic endpoints are disabled.")
+
+	flag.DurationVar(&conflictRequeueAfter, "conflict-requeue-after", 0,
+		"How long to wait before reconciling an object again after an update conflict (e.g. 1s). Conflicts are counted in capi_reconcile_conflicts_total instead of being reported as errors. If unspecified, the object is requeued with the controller's rate limiter.")
+
+	flag.DurationVar(&providerCRDRequeueAfter, "provider-crd-requeue-after", time.Minute,
+		"How long to wait before reconciling a Cluster or Machine again when the CRD of its infrastructure or bootstrap object isn't installed yet (e.g. 30s).")
+
+	flag.BoolVar(&disableMachinePoolFinalizer, "disable-machinepool-finalizer", false,
+		"Stop adding a finalizer to MachinePools. By default, deleting a MachinePool deletes its Machines and infrastructure instances first. Disable it only while an infrastructure provider tears down MachinePool instances itself.")
+
 	flag.Parse()
 
 	ctrl.SetLogger(klogr.New())
@@ -131,8 +167,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	excluded, err := parseExcludedNamespaces(excludedNamespaces)
+	if err != nil {
+		setupLog.Error(err, "invalid --excluded-namespaces flag")
+		os.Exit(1)
+	}
+	if len(excluded) > 0 {
+		setupLog.Info("Excluding namespaces from reconciliation", "namespaces", excluded)
+	}
+
 	setupChecks(mgr)
-	setupReconcilers(mgr)
+	setupReconcilers(mgr, excluded)
 	setupWebhooks(mgr)
 
 	// +kubebuilder:scaffold:builder
@@ -155,45 +200,114 @@ func setupChecks(mgr ctrl.Manager) {
 	}
 }
 
-func setupReconcilers(mgr ctrl.Manager) {
+func setupReconcilers(mgr ctrl.Manager, excluded []string) {
 	if webhookPort != 0 {
 		return
 	}
 	if err := (&controllers.ClusterReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Cluster"),
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("Cluster"),
+		ExcludedNamespaces:      excluded,
+		ConflictRequeueAfter:    conflictRequeueAfter,
+		ProviderCRDRequeueAfter: providerCRDRequeueAfter,
 	}).SetupWithManager(mgr, concurrency(clusterConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Cluster")
 		os.Exit(1)
 	}
-	if err := (&controllers.MachineReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Machine"),
-	}).SetupWithManager(mgr, concurrency(machineConcurrency)); err != nil {
+	machineReconciler := &controllers.MachineReconciler{
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("Machine"),
+		ExcludedNamespaces:      excluded,
+		ConflictRequeueAfter:    conflictRequeueAfter,
+		ProviderCRDRequeueAfter: providerCRDRequeueAfter,
+	}
+	if err := machineReconciler.SetupWithManager(mgr, concurrency(machineConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Machine")
 		os.Exit(1)
 	}
 	if err := (&controllers.MachineSetReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MachineSet"),
+		Client:               mgr.GetClient(),
+		Log:                  ctrl.Log.WithName("controllers").WithName("MachineSet"),
+		ExcludedNamespaces:   excluded,
+		ConflictRequeueAfter: conflictRequeueAfter,
 	}).SetupWithManager(mgr, concurrency(machineSetConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineSet")
 		os.Exit(1)
 	}
-	if err := (&controllers.MachineDeploymentReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MachineDeployment"),
-	}).SetupWithManager(mgr, concurrency(machineDeploymentConcurrency)); err != nil {
+	machineDeploymentReconciler := &controllers.MachineDeploymentReconciler{
+		Client:               mgr.GetClient(),
+		Log:                  ctrl.Log.WithName("controllers").WithName("MachineDeployment"),
+		ExcludedNamespaces:   excluded,
+		ConflictRequeueAfter: conflictRequeueAfter,
+	}
+	if err := machineDeploymentReconciler.SetupWithManager(mgr, concurrency(machineDeploymentConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineDeployment")
 		os.Exit(1)
 	}
 	if err := (&controllers.MachinePoolReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MachinePool"),
+		Client:               mgr.GetClient(),
+		Log:                  ctrl.Log.WithName("controllers").WithName("MachinePool"),
+		ExcludedNamespaces:   excluded,
+		ConflictRequeueAfter: conflictRequeueAfter,
+		DisableFinalizer:     disableMachinePoolFinalizer,
 	}).SetupWithManager(mgr, concurrency(machinePoolConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachinePool")
 		os.Exit(1)
 	}
+
+	setupDiagnostics(mgr, map[schema.GroupVersionKind]dryrun.ReconcilerFunc{
+		clusterv1alpha3.GroupVersion.WithKind("Machine"):           machineReconciler.DryRun,
+		clusterv1alpha3.GroupVersion.WithKind("MachineDeployment"): machineDeploymentReconciler.DryRun,
+	})
+}
+
+// setupDiagnostics serves the dry-run diff and bulk pause endpoints on the diagnostics address, if the diagnostic
+// endpoints are enabled.
+func setupDiagnostics(mgr ctrl.Manager, reconcilers map[schema.GroupVersionKind]dryrun.ReconcilerFunc) {
+	if diagnosticsTokenFile == "" {
+		return
+	}
+	token, err := diagnostics.LoadToken(diagnosticsTokenFile)
+	if err != nil {
+		setupLog.Error(err, "invalid --diagnostics-token-file flag")
+		os.Exit(1)
+	}
+
+	server := diagnostics.NewServer(diagnosticsAddr, token)
+	server.Handle("/debug/dry-run", &dryrun.Handler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Log:         ctrl.Log.WithName("dry-run"),
+		Reconcilers: reconcilers,
+	})
+
+	// Bulk pause reads objects from the API server rather than the cache, so recently created objects aren't missed.
+	pauseClient := client.DelegatingClient{
+		Reader:       mgr.GetAPIReader(),
+		Writer:       mgr.GetClient(),
+		StatusClient: mgr.GetClient(),
+	}
+	pauseKinds := []schema.GroupVersionKind{
+		clusterv1alpha3.GroupVersion.WithKind("Cluster"),
+		clusterv1alpha3.GroupVersion.WithKind("Machine"),
+		clusterv1alpha3.GroupVersion.WithKind("MachineSet"),
+		clusterv1alpha3.GroupVersion.WithKind("MachineDeployment"),
+		clusterv1alpha3.GroupVersion.WithKind("MachineHealthCheck"),
+	}
+	for path, paused := range map[string]bool{"/debug/pause": true, "/debug/unpause": false} {
+		server.Handle(path, &pause.Handler{
+			Client: pauseClient,
+			Scheme: mgr.GetScheme(),
+			Log:    ctrl.Log.WithName("pause"),
+			Kinds:  pauseKinds,
+			Pause:  paused,
+		})
+	}
+
+	if err := mgr.Add(server); err != nil {
+		setupLog.Error(err, "unable to serve the diagnostic endpoints")
+		os.Exit(1)
+	}
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
@@ -201,6 +315,22 @@ func setupWebhooks(mgr ctrl.Manager) {
 		return
 	}
 
+	// Register the defaulting and validating webhooks first, recording the admission requests they handle.
+	// Setting up the webhooks below skips the paths already registered.
+	instrumented := map[string]runtime.Object{
+		"Cluster":           &clusterv1alpha3.Cluster{},
+		"Machine":           &clusterv1alpha3.Machine{},
+		"MachineSet":        &clusterv1alpha3.MachineSet{},
+		"MachineDeployment": &clusterv1alpha3.MachineDeployment{},
+		"MachinePool":       &clusterv1alpha3.MachinePool{},
+	}
+	for kind, obj := range instrumented {
+		if err := webhookmetrics.RegisterWebhooks(mgr, obj); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", kind)
+			os.Exit(1)
+		}
+	}
+
 	if err := (&clusterv1alpha2.Cluster{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Cluster")
 		os.Exit(1)
@@ -263,6 +393,26 @@ func setupWebhooks(mgr ctrl.Manager) {
 	}
 }
 
+// parseExcludedNamespaces parses the comma-separated list of namespaces given to --excluded-namespaces,
+// returning an error if any of them isn't a valid namespace name.
+func parseExcludedNamespaces(value string) ([]string, error) {
+	var namespaces []string
+	for _, ns := range strings.Split(value, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return nil, errors.Errorf("invalid namespace %q: %s", ns, strings.Join(errs, ", "))
+		}
+		if ns == watchNamespace {
+			return nil, errors.Errorf("namespace %q is both watched and excluded", ns)
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
+
 func concurrency(c int) controller.Options {
 	return controller.Options{MaxConcurrentReconciles: c}
 }
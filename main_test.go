@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"sigs.k8s.io/cluster-api/util/diagnostics"
+)
+
+// fakeManager records the runnables added to it. Calling any other method not overridden here panics.
+type fakeManager struct {
+	ctrl.Manager
+	runnables []manager.Runnable
+}
+
+func (m *fakeManager) Add(r manager.Runnable) error {
+	m.runnables = append(m.runnables, r)
+	return nil
+}
+
+func (m *fakeManager) GetClient() client.Client {
+	return nil
+}
+
+func (m *fakeManager) GetAPIReader() client.Reader {
+	return nil
+}
+
+func (m *fakeManager) GetScheme() *runtime.Scheme {
+	return scheme
+}
+
+func TestSetupDiagnostics(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "diagnostics")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	diagnosticsTokenFile = filepath.Join(dir, "token")
+	defer func() { diagnosticsTokenFile = "" }()
+	g.Expect(ioutil.WriteFile(diagnosticsTokenFile, []byte("secret-token"), 0600)).To(Succeed())
+
+	mgr := &fakeManager{}
+	setupDiagnostics(mgr, nil)
+	g.Expect(mgr.runnables).To(HaveLen(1))
+	server, ok := mgr.runnables[0].(*diagnostics.Server)
+	g.Expect(ok).To(BeTrue())
+
+	// The requests below are rejected by the handlers before they use the manager's clients.
+	tests := []struct {
+		method     string
+		path       string
+		header     string
+		wantStatus int
+	}{
+		{method: http.MethodPost, path: "/debug/dry-run", header: "Bearer secret-token", wantStatus: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/debug/dry-run", wantStatus: http.StatusUnauthorized},
+		{method: http.MethodGet, path: "/debug/pause", header: "Bearer secret-token", wantStatus: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, path: "/debug/pause", wantStatus: http.StatusUnauthorized},
+		{method: http.MethodGet, path: "/debug/unpause", header: "Bearer secret-token", wantStatus: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, path: "/debug/unpause", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(tt.wantStatus), tt.method+" "+tt.path)
+	}
+}
+
+func TestSetupDiagnosticsDisabled(t *testing.T) {
+	g := NewWithT(t)
+
+	mgr := &fakeManager{}
+	setupDiagnostics(mgr, nil)
+	g.Expect(mgr.runnables).To(BeEmpty())
+}
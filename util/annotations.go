@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+)
+
+// MaxAnnotationsSize is the maximum total size, in bytes, of the annotations of an object accepted by the API server.
+const MaxAnnotationsSize = 256 * (1 << 10)
+
+// AnnotationsSize returns the total size of the given annotations as computed by the API server,
+// i.e. the sum of the lengths of their keys and values.
+func AnnotationsSize(annotations map[string]string) int {
+	size := 0
+	for k, v := range annotations {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// OversizedAnnotations returns the keys of the annotations that can't be propagated to an object already
+// annotated with current without its annotations growing over MaxAnnotationsSize, keeping reserved bytes
+// free for annotations set afterwards. The largest annotations are left out first, so that as many
+// annotations as possible are propagated. The returned keys are sorted.
+func OversizedAnnotations(current, propagated map[string]string, reserved int) []string {
+	merged := make(map[string]string, len(current)+len(propagated))
+	for k, v := range current {
+		merged[k] = v
+	}
+	keys := make([]string, 0, len(propagated))
+	for k, v := range propagated {
+		if cv, ok := current[k]; ok && cv == v {
+			continue
+		}
+		merged[k] = v
+		keys = append(keys, k)
+	}
+
+	size := AnnotationsSize(merged)
+	if size+reserved <= MaxAnnotationsSize {
+		return nil
+	}
+
+	// Leave out the largest annotations first, breaking ties by key to keep the result stable.
+	sort.Slice(keys, func(i, j int) bool {
+		si, sj := len(keys[i])+len(propagated[keys[i]]), len(keys[j])+len(propagated[keys[j]])
+		if si != sj {
+			return si > sj
+		}
+		return keys[i] < keys[j]
+	})
+
+	var oversized []string
+	for _, k := range keys {
+		if size+reserved <= MaxAnnotationsSize {
+			break
+		}
+		size -= len(k) + len(propagated[k])
+		if v, ok := current[k]; ok {
+			size += len(k) + len(v)
+		}
+		oversized = append(oversized, k)
+	}
+	sort.Strings(oversized)
+	return oversized
+}
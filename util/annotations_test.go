@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOversizedAnnotations(t *testing.T) {
+	large := strings.Repeat("x", MaxAnnotationsSize/2)
+
+	tests := []struct {
+		name       string
+		current    map[string]string
+		propagated map[string]string
+		reserved   int
+		want       []string
+	}{
+		{
+			name:       "annotations fitting within the limit are all propagated",
+			current:    map[string]string{"a": "1"},
+			propagated: map[string]string{"b": "2", "c": large},
+		},
+		{
+			name:       "the largest annotations are left out first",
+			current:    map[string]string{"a": large},
+			propagated: map[string]string{"b": "2", "c": large, "d": large + "x"},
+			want:       []string{"c", "d"},
+		},
+		{
+			name:       "reserved bytes are kept free",
+			propagated: map[string]string{"a": large, "b": large[:len(large)-2]},
+			reserved:   4,
+			want:       []string{"a"},
+		},
+		{
+			name:       "annotations already set to the same value are not left out",
+			current:    map[string]string{"a": large, "b": large[:len(large)-10]},
+			propagated: map[string]string{"a": large, "c": large},
+			want:       []string{"c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(OversizedAnnotations(tt.current, tt.propagated, tt.reserved)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestGetAndHas(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{}
+
+	g.Expect(Has(ms, "conditionBaz")).To(BeFalse())
+	g.Expect(Get(ms, "conditionBaz")).To(BeNil())
+
+	ms.SetConditions(clusterv1.Conditions{*TrueCondition("conditionBaz")})
+
+	g.Expect(Has(ms, "conditionBaz")).To(BeTrue())
+	g.Expect(Get(ms, "conditionBaz")).To(Equal(TrueCondition("conditionBaz")))
+}
+
+func TestIsTrueAndIsFalse(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{}
+	ms.SetConditions(clusterv1.Conditions{
+		*TrueCondition("trueCondition"),
+		*FalseCondition("falseCondition", "reason", clusterv1.ConditionSeverityError, "message"),
+	})
+
+	g.Expect(IsTrue(ms, "trueCondition")).To(BeTrue())
+	g.Expect(IsFalse(ms, "trueCondition")).To(BeFalse())
+	g.Expect(IsTrue(ms, "falseCondition")).To(BeFalse())
+	g.Expect(IsFalse(ms, "falseCondition")).To(BeTrue())
+	g.Expect(IsTrue(ms, "missingCondition")).To(BeFalse())
+	g.Expect(IsFalse(ms, "missingCondition")).To(BeFalse())
+}
+
+func TestSet(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{}
+
+	// Conditions are sorted by type, with Ready first.
+	MarkTrue(ms, "conditionB")
+	MarkTrue(ms, "conditionA")
+	MarkTrue(ms, clusterv1.ReadyCondition)
+
+	g.Expect(ms.GetConditions()).To(HaveLen(3))
+	g.Expect(ms.GetConditions()[0].Type).To(Equal(clusterv1.ReadyCondition))
+	g.Expect(ms.GetConditions()[1].Type).To(Equal(clusterv1.ConditionType("conditionA")))
+	g.Expect(ms.GetConditions()[2].Type).To(Equal(clusterv1.ConditionType("conditionB")))
+
+	for _, c := range ms.GetConditions() {
+		g.Expect(c.LastTransitionTime.IsZero()).To(BeFalse())
+	}
+}
+
+func TestSetPreservesLastTransitionTime(t *testing.T) {
+	g := NewWithT(t)
+
+	past := metav1.NewTime(time.Now().Add(-time.Hour).UTC().Truncate(time.Second))
+	ms := &clusterv1.MachineSet{}
+	ms.SetConditions(clusterv1.Conditions{
+		{
+			Type:               "conditionA",
+			Status:             corev1.ConditionFalse,
+			Reason:             "reason",
+			Severity:           clusterv1.ConditionSeverityError,
+			Message:            "message",
+			LastTransitionTime: past,
+		},
+	})
+
+	// Setting the same state doesn't change the LastTransitionTime.
+	MarkFalse(ms, "conditionA", "reason", clusterv1.ConditionSeverityError, "message")
+	g.Expect(Get(ms, "conditionA").LastTransitionTime).To(Equal(past))
+
+	// Any change in the state does.
+	MarkFalse(ms, "conditionA", "reason", clusterv1.ConditionSeverityError, "another message")
+	g.Expect(Get(ms, "conditionA").LastTransitionTime).ToNot(Equal(past))
+	g.Expect(Get(ms, "conditionA").Message).To(Equal("another message"))
+
+	MarkTrue(ms, "conditionA")
+	g.Expect(IsTrue(ms, "conditionA")).To(BeTrue())
+	g.Expect(Get(ms, "conditionA").Reason).To(BeEmpty())
+	g.Expect(Get(ms, "conditionA").Severity).To(Equal(clusterv1.ConditionSeverityNone))
+}
+
+func TestDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{}
+	MarkTrue(ms, "conditionA")
+	MarkTrue(ms, "conditionB")
+
+	Delete(ms, "conditionA")
+	g.Expect(Has(ms, "conditionA")).To(BeFalse())
+	g.Expect(Has(ms, "conditionB")).To(BeTrue())
+
+	// Deleting a missing condition is a no-op.
+	Delete(ms, "conditionA")
+	g.Expect(ms.GetConditions()).To(HaveLen(1))
+}
+
+func TestSetTransitional(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{}
+
+	SetTransitional(ms, "conditionA", false, "WaitingForSomething", "waiting for %s", "something")
+	g.Expect(IsFalse(ms, "conditionA")).To(BeTrue())
+	g.Expect(Get(ms, "conditionA").Reason).To(Equal("WaitingForSomething"))
+	g.Expect(Get(ms, "conditionA").Severity).To(Equal(clusterv1.ConditionSeverityInfo))
+	g.Expect(Get(ms, "conditionA").Message).To(Equal("waiting for something"))
+
+	// Once the state resolves, the transient reason doesn't linger.
+	SetTransitional(ms, "conditionA", true, "WaitingForSomething", "waiting for %s", "something")
+	g.Expect(IsTrue(ms, "conditionA")).To(BeTrue())
+	g.Expect(Get(ms, "conditionA").Reason).To(BeEmpty())
+	g.Expect(Get(ms, "conditionA").Message).To(BeEmpty())
+}
+
+func TestMarkFalseWithEvent(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{}
+	recorder := record.NewFakeRecorder(32)
+
+	MarkFalseWithEvent(recorder, ms, "conditionA", "SomethingWrong", clusterv1.ConditionSeverityWarning, "%s is wrong", "something")
+	g.Expect(IsFalse(ms, "conditionA")).To(BeTrue())
+	g.Expect(Get(ms, "conditionA").Reason).To(Equal("SomethingWrong"))
+	g.Expect(Get(ms, "conditionA").Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(Get(ms, "conditionA").Message).To(Equal("something is wrong"))
+	g.Expect(recorder.Events).To(Receive(Equal("Warning SomethingWrong something is wrong")))
+
+	// The event isn't repeated while the condition doesn't change.
+	MarkFalseWithEvent(recorder, ms, "conditionA", "SomethingWrong", clusterv1.ConditionSeverityWarning, "%s is wrong", "something")
+	g.Expect(recorder.Events).NotTo(Receive())
+
+	// A different reason or message is reported again.
+	MarkFalseWithEvent(recorder, ms, "conditionA", "SomethingWrong", clusterv1.ConditionSeverityWarning, "%s is wrong", "something else")
+	g.Expect(recorder.Events).To(Receive(Equal("Warning SomethingWrong something else is wrong")))
+	MarkFalseWithEvent(recorder, ms, "conditionA", "OtherProblem", clusterv1.ConditionSeverityWarning, "%s is wrong", "something else")
+	g.Expect(recorder.Events).To(Receive(Equal("Warning OtherProblem something else is wrong")))
+
+	// So is the problem coming back once the condition was true.
+	MarkTrue(ms, "conditionA")
+	MarkFalseWithEvent(recorder, ms, "conditionA", "OtherProblem", clusterv1.ConditionSeverityWarning, "%s is wrong", "something else")
+	g.Expect(recorder.Events).To(Receive(Equal("Warning OtherProblem something else is wrong")))
+}
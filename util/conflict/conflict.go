@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conflict turns optimistic-lock conflicts returned by reconcilers into quiet requeues.
+package conflict
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	// ReconcileConflictsTotal counts the reconciles that ended on an optimistic-lock conflict,
+	// which are requeued instead of being reported as errors.
+	ReconcileConflictsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capi_reconcile_conflicts_total",
+			Help: "Total number of reconciles requeued because of an optimistic-lock conflict.",
+		},
+		[]string{"controller"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(ReconcileConflictsTotal)
+}
+
+// IsConflict returns true if err is a conflict error, possibly wrapped.
+// Aggregated errors are considered conflicts only when all of them are, so a conflict
+// never hides a real error.
+func IsConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	if agg, ok := err.(kerrors.Aggregate); ok {
+		errs := agg.Errors()
+		if len(errs) == 0 {
+			return false
+		}
+		for _, e := range errs {
+			if !IsConflict(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return apierrors.IsConflict(errors.Cause(err))
+}
+
+// Reconciler wraps a reconcile.Reconciler so that conflicts returned by it are counted
+// and requeued, rather than logged and counted as reconcile errors.
+type Reconciler struct {
+	// Controller is the name of the wrapped controller, used in logs and metrics.
+	Controller string
+
+	// Reconciler is the wrapped reconciler.
+	Reconciler reconcile.Reconciler
+
+	// RequeueAfter is how long to wait before reconciling again after a conflict.
+	// If zero, the request is requeued with the controller's rate limiter.
+	RequeueAfter time.Duration
+
+	Log logr.Logger
+}
+
+// Reconcile implements reconcile.Reconciler.
+func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	result, err := r.Reconciler.Reconcile(req)
+	if !IsConflict(err) {
+		return result, err
+	}
+
+	ReconcileConflictsTotal.WithLabelValues(r.Controller).Inc()
+	r.Log.V(4).Info("Requeueing after a conflict", "request", req.NamespacedName, "error", err.Error())
+
+	if r.RequeueAfter > 0 {
+		return reconcile.Result{RequeueAfter: r.RequeueAfter}, nil
+	}
+	return reconcile.Result{Requeue: true}, nil
+}
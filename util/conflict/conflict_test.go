@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conflict
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var conflictErr = apierrors.NewConflict(schema.GroupResource{Group: "cluster.x-k8s.io", Resource: "machines"}, "foo", errors.New("object has been modified"))
+
+func TestIsConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+		},
+		{
+			name: "conflict",
+			err:  conflictErr,
+			want: true,
+		},
+		{
+			name: "wrapped conflict",
+			err:  errors.Wrap(conflictErr, "failed to patch Machine"),
+			want: true,
+		},
+		{
+			name: "aggregate of conflicts",
+			err:  kerrors.NewAggregate([]error{conflictErr, errors.Wrap(conflictErr, "failed to patch Machine")}),
+			want: true,
+		},
+		{
+			name: "aggregate with another error",
+			err:  kerrors.NewAggregate([]error{conflictErr, errors.New("boom")}),
+		},
+		{
+			name: "another error",
+			err:  apierrors.NewBadRequest("boom"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(IsConflict(tt.err)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestReconciler(t *testing.T) {
+	tests := []struct {
+		name          string
+		controller    string
+		requeueAfter  time.Duration
+		err           error
+		wantResult    reconcile.Result
+		wantErr       bool
+		wantConflicts float64
+	}{
+		{
+			name:       "passes through a successful reconcile",
+			controller: "TestReconcilerSuccess",
+			wantResult: reconcile.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:       "passes through errors",
+			controller: "TestReconcilerError",
+			err:        errors.New("boom"),
+			wantResult: reconcile.Result{RequeueAfter: time.Minute},
+			wantErr:    true,
+		},
+		{
+			name:          "requeues on conflict",
+			controller:    "TestReconcilerConflict",
+			err:           errors.Wrap(conflictErr, "failed to patch Machine"),
+			wantResult:    reconcile.Result{Requeue: true},
+			wantConflicts: 1,
+		},
+		{
+			name:          "requeues after the configured delay on conflict",
+			controller:    "TestReconcilerConflictRequeueAfter",
+			requeueAfter:  time.Second,
+			err:           conflictErr,
+			wantResult:    reconcile.Result{RequeueAfter: time.Second},
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &Reconciler{
+				Controller: tt.controller,
+				Reconciler: reconcile.Func(func(reconcile.Request) (reconcile.Result, error) {
+					return reconcile.Result{RequeueAfter: time.Minute}, tt.err
+				}),
+				RequeueAfter: tt.requeueAfter,
+				Log:          log.Log,
+			}
+
+			result, err := r.Reconcile(reconcile.Request{})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(result).To(Equal(tt.wantResult))
+			g.Expect(testutil.ToFloat64(ReconcileConflictsTotal.WithLabelValues(tt.controller))).To(Equal(tt.wantConflicts))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diagnostics contains helpers to serve and protect the diagnostic endpoints of the managers.
+package diagnostics
+
+import (
+	"context"
+	"crypto/subtle"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// LoadToken reads the bearer token protecting the diagnostic endpoints from the given file.
+func LoadToken(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read diagnostics token file %q", path)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.Errorf("diagnostics token file %q is empty", path)
+	}
+	return token, nil
+}
+
+// Authenticate wraps a handler so it's only served to requests presenting the given bearer token.
+func Authenticate(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		auth := req.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// Server serves the diagnostic endpoints on their own address, every one of them protected by a bearer token.
+// It is run by the manager, and also serves the endpoints on managers that aren't the leader.
+type Server struct {
+	addr  string
+	token string
+	mux   *http.ServeMux
+}
+
+var _ manager.LeaderElectionRunnable = &Server{}
+var _ http.Handler = &Server{}
+
+// NewServer returns a Server listening on the given address and protected by the given bearer token.
+func NewServer(addr, token string) *Server {
+	return &Server{
+		addr:  addr,
+		token: token,
+		mux:   http.NewServeMux(),
+	}
+}
+
+// Handle registers the handler for the given path, behind the server's bearer token.
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.mux.Handle(path, Authenticate(s.token, handler))
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+// Start implements manager.Runnable, serving the diagnostic endpoints until stop is closed.
+func (s *Server) Start(stop <-chan struct{}) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on diagnostics address %q", s.addr)
+	}
+
+	server := &http.Server{Handler: s.mux}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case <-stop:
+		return server.Shutdown(context.Background())
+	case err := <-errCh:
+		return errors.Wrap(err, "failed to serve the diagnostic endpoints")
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoadToken(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "diagnostics")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	g.Expect(ioutil.WriteFile(path, []byte("secret-token\n"), 0600)).To(Succeed())
+	token, err := LoadToken(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(token).To(Equal("secret-token"))
+
+	g.Expect(ioutil.WriteFile(path, []byte("  \n"), 0600)).To(Succeed())
+	_, err = LoadToken(path)
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = LoadToken(filepath.Join(dir, "missing"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestAuthenticate(t *testing.T) {
+	handler := Authenticate("secret-token", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{
+			name:       "valid token",
+			header:     "Bearer secret-token",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid token",
+			header:     "Bearer other-token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unsupported scheme",
+			header:     "Basic secret-token",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			g.Expect(rec.Code).To(Equal(tt.wantStatus))
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	g := NewWithT(t)
+
+	server := NewServer("127.0.0.1:0", "secret-token")
+	server.Handle("/debug/test", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path       string
+		header     string
+		wantStatus int
+	}{
+		{path: "/debug/test", header: "Bearer secret-token", wantStatus: http.StatusOK},
+		{path: "/debug/test", wantStatus: http.StatusUnauthorized},
+		{path: "/debug/other", header: "Bearer secret-token", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(tt.wantStatus), tt.path)
+	}
+}
+
+func TestServerStart(t *testing.T) {
+	g := NewWithT(t)
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- NewServer("127.0.0.1:0", "secret-token").Start(stop)
+	}()
+	close(stop)
+	g.Eventually(done).Should(Receive(BeNil()))
+
+	// A server that can't listen on its address reports it.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer listener.Close()
+	g.Expect(NewServer(listener.Addr().String(), "secret-token").Start(make(chan struct{}))).NotTo(Succeed())
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dryrun implements running reconcilers without applying their changes, to preview what
+// they would do to an object.
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Operation is a write operation recorded by the dry-run Client.
+type Operation string
+
+const (
+	// OperationCreate is recorded when an object would be created.
+	OperationCreate = Operation("create")
+
+	// OperationUpdate is recorded when an object would be updated.
+	OperationUpdate = Operation("update")
+
+	// OperationPatch is recorded when an object would be patched.
+	OperationPatch = Operation("patch")
+
+	// OperationDelete is recorded when an object would be deleted.
+	OperationDelete = Operation("delete")
+
+	// OperationDeleteAllOf is recorded when a collection of objects would be deleted.
+	OperationDeleteAllOf = Operation("deleteAllOf")
+)
+
+// Change is a write that a reconciler would have applied.
+type Change struct {
+	Operation Operation `json:"operation"`
+
+	// Subresource is set to "status" for writes to the status subresource.
+	Subresource string `json:"subresource,omitempty"`
+
+	APIVersion   string `json:"apiVersion"`
+	Kind         string `json:"kind"`
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name,omitempty"`
+	GenerateName string `json:"generateName,omitempty"`
+
+	// PatchType and Patch describe the changes to the live object, for updates and patches.
+	// Updates are reported as a JSON merge patch against the live object.
+	PatchType types.PatchType `json:"patchType,omitempty"`
+	Patch     json.RawMessage `json:"patch,omitempty"`
+
+	// Object is the object that would be created.
+	Object runtime.Object `json:"object,omitempty"`
+}
+
+// Client is a client.Client that reads through the wrapped client, and records writes instead of applying them.
+type Client struct {
+	client.Client
+
+	scheme *runtime.Scheme
+
+	lock    sync.Mutex
+	changes []Change
+}
+
+var _ client.Client = &Client{}
+
+// NewClient returns a dry-run Client wrapping the given client.
+func NewClient(c client.Client, scheme *runtime.Scheme) *Client {
+	return &Client{
+		Client: c,
+		scheme: scheme,
+	}
+}
+
+// Changes returns the writes recorded so far, in order.
+func (c *Client) Changes() []Change {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	changes := make([]Change, len(c.changes))
+	copy(changes, c.changes)
+	return changes
+}
+
+// Create records the creation of the object.
+func (c *Client) Create(_ context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+	change, err := c.newChange(OperationCreate, "", obj)
+	if err != nil {
+		return err
+	}
+	change.Object = obj.DeepCopyObject()
+	c.record(change)
+	return nil
+}
+
+// Update records the update of the object, as a merge patch against the live object.
+func (c *Client) Update(ctx context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+	return c.recordUpdate(ctx, "", obj)
+}
+
+// Patch records the patch of the object.
+func (c *Client) Patch(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+	return c.recordPatch("", obj, patch)
+}
+
+// Delete records the deletion of the object.
+func (c *Client) Delete(_ context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+	change, err := c.newChange(OperationDelete, "", obj)
+	if err != nil {
+		return err
+	}
+	c.record(change)
+	return nil
+}
+
+// DeleteAllOf records the deletion of the objects of the given type.
+func (c *Client) DeleteAllOf(_ context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	change, err := c.newChange(OperationDeleteAllOf, "", obj)
+	if err != nil {
+		return err
+	}
+	deleteAllOfOpts := &client.DeleteAllOfOptions{}
+	deleteAllOfOpts.ApplyOptions(opts)
+	change.Namespace = deleteAllOfOpts.Namespace
+	c.record(change)
+	return nil
+}
+
+// Status returns a client.StatusWriter recording the writes to the status subresource.
+func (c *Client) Status() client.StatusWriter {
+	return &statusWriter{client: c}
+}
+
+type statusWriter struct {
+	client *Client
+}
+
+// Update records the update of the object status, as a merge patch against the live object.
+func (s *statusWriter) Update(ctx context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+	return s.client.recordUpdate(ctx, "status", obj)
+}
+
+// Patch records the patch of the object status.
+func (s *statusWriter) Patch(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+	return s.client.recordPatch("status", obj, patch)
+}
+
+func (c *Client) recordUpdate(ctx context.Context, subresource string, obj runtime.Object) error {
+	change, err := c.newChange(OperationUpdate, subresource, obj)
+	if err != nil {
+		return err
+	}
+
+	live := obj.DeepCopyObject()
+	if err := c.Client.Get(ctx, client.ObjectKey{Namespace: change.Namespace, Name: change.Name}, live); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get live %s %s/%s", change.Kind, change.Namespace, change.Name)
+		}
+		// The update would fail, report the whole object.
+		change.Object = obj.DeepCopyObject()
+		c.record(change)
+		return nil
+	}
+
+	patch := client.MergeFrom(live)
+	if change.Patch, err = patch.Data(obj); err != nil {
+		return errors.Wrapf(err, "failed to compute changes to %s %s/%s", change.Kind, change.Namespace, change.Name)
+	}
+	change.PatchType = patch.Type()
+	c.record(change)
+	return nil
+}
+
+func (c *Client) recordPatch(subresource string, obj runtime.Object, patch client.Patch) error {
+	change, err := c.newChange(OperationPatch, subresource, obj)
+	if err != nil {
+		return err
+	}
+	if change.Patch, err = patch.Data(obj); err != nil {
+		return errors.Wrapf(err, "failed to compute changes to %s %s/%s", change.Kind, change.Namespace, change.Name)
+	}
+	change.PatchType = patch.Type()
+	c.record(change)
+	return nil
+}
+
+func (c *Client) newChange(op Operation, subresource string, obj runtime.Object) (Change, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return Change{}, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return Change{}, err
+	}
+	return Change{
+		Operation:    op,
+		Subresource:  subresource,
+		APIVersion:   gvk.GroupVersion().String(),
+		Kind:         gvk.Kind,
+		Namespace:    accessor.GetNamespace(),
+		Name:         accessor.GetName(),
+		GenerateName: accessor.GetGenerateName(),
+	}, nil
+}
+
+func (c *Client) record(change Change) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.changes = append(c.changes, change)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func setupScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	return scheme
+}
+
+func TestClient(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	scheme := setupScheme()
+
+	live := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default"},
+		Data:       map[string]string{"key": "value"},
+	}
+	fakeClient := fake.NewFakeClientWithScheme(scheme, live.DeepCopy())
+	c := NewClient(fakeClient, scheme)
+
+	// Create.
+	created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{GenerateName: "new-", Namespace: "default"}}
+	g.Expect(c.Create(ctx, created)).To(Succeed())
+
+	// Update.
+	updated := &corev1.ConfigMap{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "live"}, updated)).To(Succeed())
+	updated.Data["key"] = "updated"
+	g.Expect(c.Update(ctx, updated)).To(Succeed())
+
+	// Patch.
+	patched := updated.DeepCopy()
+	patch := client.MergeFrom(patched.DeepCopy())
+	patched.Labels = map[string]string{"foo": "bar"}
+	g.Expect(c.Patch(ctx, patched, patch)).To(Succeed())
+
+	// Delete.
+	g.Expect(c.Delete(ctx, updated)).To(Succeed())
+
+	changes := c.Changes()
+	g.Expect(changes).To(HaveLen(4))
+
+	g.Expect(changes[0].Operation).To(Equal(OperationCreate))
+	g.Expect(changes[0].Kind).To(Equal("ConfigMap"))
+	g.Expect(changes[0].APIVersion).To(Equal("v1"))
+	g.Expect(changes[0].GenerateName).To(Equal("new-"))
+	g.Expect(changes[0].Object).NotTo(BeNil())
+
+	g.Expect(changes[1].Operation).To(Equal(OperationUpdate))
+	g.Expect(changes[1].Name).To(Equal("live"))
+	g.Expect(changes[1].PatchType).To(Equal(types.MergePatchType))
+	g.Expect(string(changes[1].Patch)).To(Equal(`{"data":{"key":"updated"}}`))
+
+	g.Expect(changes[2].Operation).To(Equal(OperationPatch))
+	g.Expect(string(changes[2].Patch)).To(Equal(`{"metadata":{"labels":{"foo":"bar"}}}`))
+
+	g.Expect(changes[3].Operation).To(Equal(OperationDelete))
+	g.Expect(changes[3].Name).To(Equal("live"))
+
+	// Nothing has been written.
+	got := &corev1.ConfigMap{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "live"}, got)).To(Succeed())
+	g.Expect(got.Data).To(Equal(live.Data))
+	g.Expect(got.Labels).To(BeEmpty())
+
+	list := &corev1.ConfigMapList{}
+	g.Expect(fakeClient.List(ctx, list)).To(Succeed())
+	g.Expect(list.Items).To(HaveLen(1))
+}
+
+func TestClientStatus(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	scheme := setupScheme()
+
+	c := NewClient(fake.NewFakeClientWithScheme(scheme, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}), scheme)
+
+	pod := &corev1.Pod{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pod"}, pod)).To(Succeed())
+	pod.Status.Phase = corev1.PodRunning
+	g.Expect(c.Status().Update(ctx, pod)).To(Succeed())
+
+	changes := c.Changes()
+	g.Expect(changes).To(HaveLen(1))
+	g.Expect(changes[0].Operation).To(Equal(OperationUpdate))
+	g.Expect(changes[0].Subresource).To(Equal("status"))
+	g.Expect(string(changes[0].Patch)).To(Equal(`{"status":{"phase":"Running"}}`))
+}
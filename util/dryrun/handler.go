@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcilerFunc returns a reconciler using the given client for all its reads and writes.
+type ReconcilerFunc func(c client.Client) reconcile.Reconciler
+
+// Result is the response of the dry-run Handler.
+type Result struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+
+	// Changes are the writes the reconciler would have applied, in order.
+	Changes []Change `json:"changes"`
+
+	// Requeue and RequeueAfter report the result of the reconcile.
+	Requeue      bool   `json:"requeue,omitempty"`
+	RequeueAfter string `json:"requeueAfter,omitempty"`
+
+	// Error is the error returned by the reconcile, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Handler serves the changes a single reconcile would apply to an object, without applying them.
+// The object is selected with the kind, namespace and name query parameters.
+//
+// The reconcile runs with a dry-run Client scoped to the request: reads go through the manager's
+// client, while writes are recorded and returned. Objects being deleted are refused, since
+// deletion is not only driven through the management cluster client.
+type Handler struct {
+	Client      client.Client
+	Scheme      *runtime.Scheme
+	Log         logr.Logger
+	Reconcilers map[schema.GroupVersionKind]ReconcilerFunc
+}
+
+var _ http.Handler = &Handler{}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := req.URL.Query()
+	kind, namespace, name := query.Get("kind"), query.Get("namespace"), query.Get("name")
+	if kind == "" || name == "" {
+		http.Error(w, "the kind and name query parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		gvk           schema.GroupVersionKind
+		newReconciler ReconcilerFunc
+	)
+	for k, f := range h.Reconcilers {
+		if k.Kind == kind {
+			gvk, newReconciler = k, f
+			break
+		}
+	}
+	if newReconciler == nil {
+		http.Error(w, fmt.Sprintf("dry-run is not supported for kind %q", kind), http.StatusNotFound)
+		return
+	}
+
+	obj, err := h.Scheme.New(gvk)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	if err := h.Client.Get(context.Background(), key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("%s %s not found", kind, key), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !accessor.GetDeletionTimestamp().IsZero() {
+		http.Error(w, fmt.Sprintf("%s %s is being deleted, dry-run is not supported for deletions", kind, key), http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.Log.Info("Running dry-run reconcile", "kind", kind, "namespace", namespace, "name", name)
+	dryRunClient := NewClient(h.Client, h.Scheme)
+	res, err := newReconciler(dryRunClient).Reconcile(reconcile.Request{NamespacedName: key})
+
+	result := Result{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+		Changes:    dryRunClient.Changes(),
+		Requeue:    res.Requeue,
+	}
+	if res.RequeueAfter > 0 {
+		result.RequeueAfter = res.RequeueAfter.String()
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.Log.Error(err, "Failed to write dry-run result")
+	}
+}
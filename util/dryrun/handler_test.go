@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// labelReconciler adds a label to the ConfigMaps it reconciles.
+type labelReconciler struct {
+	client client.Client
+}
+
+func (r *labelReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.Background(), req.NamespacedName, cm); err != nil {
+		return reconcile.Result{}, err
+	}
+	patch := client.MergeFrom(cm.DeepCopy())
+	cm.Labels = map[string]string{"reconciled": "true"}
+	return reconcile.Result{}, r.client.Patch(context.Background(), cm, patch)
+}
+
+func TestHandler(t *testing.T) {
+	scheme := setupScheme()
+	now := metav1.Now()
+	fakeClient := fake.NewFakeClientWithScheme(scheme,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "deleting", Namespace: "default", DeletionTimestamp: &now}},
+	)
+
+	h := &Handler{
+		Client: fakeClient,
+		Scheme: scheme,
+		Log:    log.Log,
+		Reconcilers: map[schema.GroupVersionKind]ReconcilerFunc{
+			corev1.SchemeGroupVersion.WithKind("ConfigMap"): func(c client.Client) reconcile.Reconciler {
+				return &labelReconciler{client: c}
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		wantStatus int
+	}{
+		{
+			name:       "reconcile preview",
+			query:      "kind=ConfigMap&namespace=default&name=cm",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unsupported method",
+			method:     http.MethodPost,
+			query:      "kind=ConfigMap&namespace=default&name=cm",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "missing name",
+			query:      "kind=ConfigMap&namespace=default",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported kind",
+			query:      "kind=Secret&namespace=default&name=cm",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "object not found",
+			query:      "kind=ConfigMap&namespace=default&name=missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "object being deleted",
+			query:      "kind=ConfigMap&namespace=default&name=deleting",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, "/?"+tt.query, nil))
+			g.Expect(rec.Code).To(Equal(tt.wantStatus))
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			result := &struct {
+				Kind    string
+				Name    string
+				Changes []struct {
+					Operation Operation
+					Name      string
+					Patch     json.RawMessage
+				}
+				Error string
+			}{}
+			g.Expect(json.Unmarshal(rec.Body.Bytes(), result)).To(Succeed())
+			g.Expect(result.Kind).To(Equal("ConfigMap"))
+			g.Expect(result.Error).To(BeEmpty())
+			g.Expect(result.Changes).To(HaveLen(1))
+			g.Expect(result.Changes[0].Operation).To(Equal(OperationPatch))
+			g.Expect(string(result.Changes[0].Patch)).To(Equal(`{"metadata":{"labels":{"reconciled":"true"}}}`))
+
+			// The change has not been applied.
+			cm := &corev1.ConfigMap{}
+			g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "cm"}, cm)).To(Succeed())
+			g.Expect(cm.Labels).To(BeEmpty())
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -45,11 +46,31 @@ func FromSecret(ctx context.Context, c client.Client, cluster *clusterv1.Cluster
 	if err != nil {
 		return nil, err
 	}
-	data, ok := out.Data[secret.KubeconfigDataName]
+	if err := ValidateSecret(out); err != nil {
+		return nil, err
+	}
+	return out.Data[secret.KubeconfigDataName], nil
+}
+
+// ValidateSecret checks that a Kubeconfig secret stores a parsable kubeconfig
+// under the expected data key, and returns an error describing the problem otherwise.
+func ValidateSecret(s *corev1.Secret) error {
+	data, ok := s.Data[secret.KubeconfigDataName]
 	if !ok {
-		return nil, errors.Errorf("missing key %q in secret data", secret.KubeconfigDataName)
+		keys := make([]string, 0, len(s.Data))
+		for k := range s.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return errors.Errorf("missing key %q in secret %s/%s data, found keys %v", secret.KubeconfigDataName, s.Namespace, s.Name, keys)
+	}
+	if len(data) == 0 {
+		return errors.Errorf("key %q in secret %s/%s is empty", secret.KubeconfigDataName, s.Namespace, s.Name)
+	}
+	if _, err := clientcmd.RESTConfigFromKubeConfig(data); err != nil {
+		return errors.Wrapf(err, "failed to parse kubeconfig stored under key %q in secret %s/%s", secret.KubeconfigDataName, s.Namespace, s.Name)
 	}
-	return data, nil
+	return nil
 }
 
 // New creates a new Kubeconfig using the cluster name and specified endpoint.
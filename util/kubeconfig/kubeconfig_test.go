@@ -97,6 +97,50 @@ func TestGetKubeConfigSecret(t *testing.T) {
 	}
 }
 
+func TestValidateSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string][]byte
+		wantErr string
+	}{
+		{
+			name: "valid kubeconfig",
+			data: validSecret.Data,
+		},
+		{
+			name:    "missing value key",
+			data:    map[string][]byte{"kubeconfig": []byte(validKubeConfig)},
+			wantErr: `missing key "value" in secret test/test1-kubeconfig data, found keys [kubeconfig]`,
+		},
+		{
+			name:    "empty value",
+			data:    map[string][]byte{secret.KubeconfigDataName: {}},
+			wantErr: `key "value" in secret test/test1-kubeconfig is empty`,
+		},
+		{
+			name:    "truncated kubeconfig",
+			data:    map[string][]byte{secret.KubeconfigDataName: []byte(validKubeConfig[:100])},
+			wantErr: `failed to parse kubeconfig stored under key "value" in secret test/test1-kubeconfig`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := validSecret.DeepCopy()
+			s.Data = tt.data
+
+			err := ValidateSecret(s)
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+		})
+	}
+}
+
 func getTestCACert(key *rsa.PrivateKey) (*x509.Certificate, error) {
 	cfg := certs.Config{
 		CommonName: "kubernetes",
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pause serves an endpoint pausing or unpausing the objects matching a label selector.
+package pause
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Result is the response of the pause Handler.
+type Result struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Selector  string `json:"selector"`
+
+	// Paused is true if the objects were paused, and false if they were unpaused.
+	Paused bool `json:"paused"`
+
+	// Count is the number of objects whose paused annotation was added or removed.
+	// Objects that were already in the requested state are not counted.
+	Count int `json:"count"`
+
+	// Objects are the namespaced names of the objects counted in Count.
+	Objects []string `json:"objects"`
+
+	// Errors are the errors hit while updating objects, if any.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Handler adds or removes the paused annotation on all the objects of a kind matching a label selector.
+// The objects are selected with the kind, selector and, optionally, namespace query parameters; the
+// selector is required so that a request can't pause every object of a kind by mistake.
+//
+// Objects are listed and patched with Client, which should not read from the manager's cache so
+// that objects created or unpaused just before the request are not missed.
+type Handler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+	Kinds  []schema.GroupVersionKind
+
+	// Pause is true if the handler pauses the selected objects, and false if it unpauses them.
+	Pause bool
+}
+
+var _ http.Handler = &Handler{}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := req.URL.Query()
+	kind, namespace, selector := query.Get("kind"), query.Get("namespace"), query.Get("selector")
+	if kind == "" || selector == "" {
+		http.Error(w, "the kind and selector query parameters are required", http.StatusBadRequest)
+		return
+	}
+	labelSelector, err := labels.Parse(selector)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid selector %q: %v", selector, err), http.StatusBadRequest)
+		return
+	}
+
+	var gvk schema.GroupVersionKind
+	for _, k := range h.Kinds {
+		if k.Kind == kind {
+			gvk = k
+			break
+		}
+	}
+	if gvk.Empty() {
+		http.Error(w, fmt.Sprintf("pausing is not supported for kind %q", kind), http.StatusNotFound)
+		return
+	}
+
+	list, err := h.Scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ctx := context.Background()
+	if err := h.Client.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := Result{
+		Kind:      kind,
+		Namespace: namespace,
+		Selector:  selector,
+		Paused:    h.Pause,
+		Objects:   []string{},
+	}
+	for _, obj := range objs {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		name := client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}.String()
+
+		changed, err := h.setPaused(ctx, obj, accessor)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s: %v", kind, name, err))
+			continue
+		}
+		if changed {
+			result.Count++
+			result.Objects = append(result.Objects, name)
+		}
+	}
+	h.Log.Info("Updated paused annotation", "kind", kind, "namespace", namespace, "selector", selector,
+		"paused", h.Pause, "count", result.Count, "errors", len(result.Errors))
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Errors) > 0 {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.Log.Error(err, "Failed to write pause result")
+	}
+}
+
+// setPaused adds or removes the paused annotation on obj, and returns true if it was changed.
+func (h *Handler) setPaused(ctx context.Context, obj runtime.Object, accessor metav1.Object) (bool, error) {
+	annotations := accessor.GetAnnotations()
+	if _, paused := annotations[clusterv1.PausedAnnotation]; paused == h.Pause {
+		return false, nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject())
+	if h.Pause {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[clusterv1.PausedAnnotation] = "true"
+	} else {
+		delete(annotations, clusterv1.PausedAnnotation)
+	}
+	accessor.SetAnnotations(annotations)
+
+	if err := h.Client.Patch(ctx, obj, patch); err != nil {
+		return false, err
+	}
+	return true, nil
+}
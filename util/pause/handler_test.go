@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pause
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	newMachine := func(name, namespace string, labels, annotations map[string]string) *clusterv1.Machine {
+		return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		}}
+	}
+	maintenance := map[string]string{"maintenance": "true"}
+	paused := map[string]string{clusterv1.PausedAnnotation: "true"}
+	fakeClient := fake.NewFakeClientWithScheme(scheme,
+		newMachine("machine-1", "default", maintenance, nil),
+		newMachine("machine-2", "default", maintenance, paused),
+		newMachine("machine-3", "default", nil, nil),
+		newMachine("machine-4", "other", maintenance, nil),
+	)
+
+	newHandler := func(pause bool) *Handler {
+		return &Handler{
+			Client: fakeClient,
+			Scheme: scheme,
+			Log:    log.Log,
+			Kinds:  []schema.GroupVersionKind{clusterv1.GroupVersion.WithKind("Machine")},
+			Pause:  pause,
+		}
+	}
+	isPaused := func(g *WithT, namespace, name string) bool {
+		m := &clusterv1.Machine{}
+		g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, m)).To(Succeed())
+		_, ok := m.Annotations[clusterv1.PausedAnnotation]
+		return ok
+	}
+	serve := func(g *WithT, h *Handler, method, query string) (*httptest.ResponseRecorder, *Result) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/?"+query, nil))
+		if rec.Code != http.StatusOK {
+			return rec, nil
+		}
+		result := &Result{}
+		g.Expect(json.Unmarshal(rec.Body.Bytes(), result)).To(Succeed())
+		return rec, result
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rec, _ := serve(g, newHandler(true), http.MethodGet, "kind=Machine&selector=maintenance%3Dtrue")
+		g.Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+		rec, _ = serve(g, newHandler(true), http.MethodPost, "kind=Machine")
+		g.Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		rec, _ = serve(g, newHandler(true), http.MethodPost, "kind=Machine&selector=%3D%3D")
+		g.Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		rec, _ = serve(g, newHandler(true), http.MethodPost, "kind=Cluster&selector=maintenance%3Dtrue")
+		g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	t.Run("pause objects matching the selector in a namespace", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rec, result := serve(g, newHandler(true), http.MethodPost, "kind=Machine&namespace=default&selector=maintenance%3Dtrue")
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		g.Expect(result.Paused).To(BeTrue())
+		g.Expect(result.Count).To(Equal(1))
+		g.Expect(result.Objects).To(ConsistOf("default/machine-1"))
+
+		g.Expect(isPaused(g, "default", "machine-1")).To(BeTrue())
+		g.Expect(isPaused(g, "default", "machine-2")).To(BeTrue())
+		g.Expect(isPaused(g, "default", "machine-3")).To(BeFalse())
+		g.Expect(isPaused(g, "other", "machine-4")).To(BeFalse())
+	})
+
+	t.Run("unpause objects matching the selector in all namespaces", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rec, result := serve(g, newHandler(false), http.MethodPost, "kind=Machine&selector=maintenance%3Dtrue")
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		g.Expect(result.Paused).To(BeFalse())
+		g.Expect(result.Count).To(Equal(2))
+		g.Expect(result.Objects).To(ConsistOf("default/machine-1", "default/machine-2"))
+
+		g.Expect(isPaused(g, "default", "machine-1")).To(BeFalse())
+		g.Expect(isPaused(g, "default", "machine-2")).To(BeFalse())
+	})
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package predicates implements predicates shared by the Cluster API controllers.
+package predicates
+
+import (
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ExcludeNamespaces returns a predicate that drops the events of objects living in any of the given namespaces.
+// When no namespace is given, all the events are processed.
+func ExcludeNamespaces(logger logr.Logger, namespaces []string) predicate.Funcs {
+	excluded := sets.NewString(namespaces...)
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return processIfNotExcluded(logger, excluded, e.Meta)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return processIfNotExcluded(logger, excluded, e.MetaNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return processIfNotExcluded(logger, excluded, e.Meta)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return processIfNotExcluded(logger, excluded, e.Meta)
+		},
+	}
+}
+
+func processIfNotExcluded(logger logr.Logger, excluded sets.String, meta metav1.Object) bool {
+	if meta == nil || excluded.Len() == 0 {
+		return true
+	}
+	if excluded.Has(meta.GetNamespace()) {
+		logger.V(6).Info("Ignoring event for object in excluded namespace", "namespace", meta.GetNamespace(), "name", meta.GetName())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestExcludeNamespaces(t *testing.T) {
+	testCases := []struct {
+		name       string
+		namespaces []string
+		namespace  string
+		expected   bool
+	}{
+		{
+			name:      "should process all the events without excluded namespaces",
+			namespace: "default",
+			expected:  true,
+		},
+		{
+			name:       "should process events for objects in other namespaces",
+			namespaces: []string{"test", "kube-system"},
+			namespace:  "default",
+			expected:   true,
+		},
+		{
+			name:       "should drop events for objects in an excluded namespace",
+			namespaces: []string{"test", "kube-system"},
+			namespace:  "test",
+			expected:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machine",
+					Namespace: tc.namespace,
+				},
+			}
+			p := ExcludeNamespaces(log.Log, tc.namespaces)
+
+			g.Expect(p.Create(event.CreateEvent{Meta: m, Object: m})).To(Equal(tc.expected))
+			g.Expect(p.Update(event.UpdateEvent{MetaOld: m, ObjectOld: m, MetaNew: m, ObjectNew: m})).To(Equal(tc.expected))
+			g.Expect(p.Delete(event.DeleteEvent{Meta: m, Object: m})).To(Equal(tc.expected))
+			g.Expect(p.Generic(event.GenericEvent{Meta: m, Object: m})).To(Equal(tc.expected))
+		})
+	}
+}
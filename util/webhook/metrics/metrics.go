@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics records the admission requests handled by the Cluster API webhooks.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// OperationDefault is the operation reported for the requests handled by defaulting webhooks.
+// Validating webhooks report the operation of the request, e.g. "create".
+const OperationDefault = "default"
+
+// Results reported in the webhook metrics.
+const (
+	// ResultAllowed is reported when the request is admitted.
+	ResultAllowed = "allowed"
+
+	// ResultDenied is reported when the request is rejected because the object is invalid or forbidden.
+	ResultDenied = "denied"
+
+	// ResultError is reported when the request is rejected for any other reason, e.g. a malformed request.
+	ResultError = "error"
+)
+
+var (
+	// RequestsTotal is a metric that counts the admission requests handled by the webhooks.
+	RequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capi_webhook_requests_total",
+			Help: "Total number of admission requests handled by the webhooks, partitioned by kind, operation and result.",
+		},
+		[]string{"kind", "operation", "result"},
+	)
+
+	// RequestDuration is a metric that observes the time spent handling admission requests in the webhooks.
+	RequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capi_webhook_request_duration_seconds",
+			Help:    "Time spent handling admission requests in the webhooks, partitioned by kind and operation.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 12),
+		},
+		[]string{"kind", "operation"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		RequestsTotal,
+		RequestDuration,
+	)
+}
+
+// RegisterWebhooks registers the defaulting and validating webhooks of obj on the webhook server of mgr,
+// recording the admission requests they handle. It must be called before the webhooks of obj are set up
+// with ctrl.NewWebhookManagedBy, which skips the paths already registered and only adds the conversion webhook.
+func RegisterWebhooks(mgr ctrl.Manager, obj runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	if defaulter, ok := obj.(admission.Defaulter); ok {
+		wh := admission.DefaultingWebhookFor(defaulter)
+		wh.Handler = &handler{kind: gvk.Kind, mutating: true, handler: wh.Handler}
+		mgr.GetWebhookServer().Register(webhookPath("mutate", gvk), wh)
+	}
+	if validator, ok := obj.(admission.Validator); ok {
+		wh := admission.ValidatingWebhookFor(validator)
+		wh.Handler = &handler{kind: gvk.Kind, handler: wh.Handler}
+		mgr.GetWebhookServer().Register(webhookPath("validate", gvk), wh)
+	}
+	return nil
+}
+
+// webhookPath returns the path controller-runtime serves the webhooks of the given type on for gvk,
+// which is the path the webhook configurations are generated with.
+func webhookPath(webhookType string, gvk schema.GroupVersionKind) string {
+	return "/" + webhookType + "-" + strings.Replace(gvk.Group, ".", "-", -1) + "-" + gvk.Version + "-" + strings.ToLower(gvk.Kind)
+}
+
+// handler decorates an admission handler, recording the requests it handles for kind.
+type handler struct {
+	kind     string
+	mutating bool
+	handler  admission.Handler
+}
+
+var _ admission.DecoderInjector = &handler{}
+
+// InjectDecoder injects the decoder into the decorated handler.
+func (h *handler) InjectDecoder(d *admission.Decoder) error {
+	_, err := admission.InjectDecoderInto(d, h.handler)
+	return err
+}
+
+// Handle handles the request with the decorated handler, recording the request and its result.
+func (h *handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	start := time.Now()
+	resp := h.handler.Handle(ctx, req)
+
+	operation := OperationDefault
+	if !h.mutating {
+		operation = strings.ToLower(string(req.Operation))
+	}
+	RequestDuration.WithLabelValues(h.kind, operation).Observe(time.Since(start).Seconds())
+	RequestsTotal.WithLabelValues(h.kind, operation, result(resp)).Inc()
+	return resp
+}
+
+func result(resp admission.Response) string {
+	switch {
+	case resp.Allowed:
+		return ResultAllowed
+	case resp.Result != nil && resp.Result.Code == http.StatusForbidden:
+		return ResultDenied
+	default:
+		return ResultError
+	}
+}
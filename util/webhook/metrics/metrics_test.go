@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		kind          string
+		mutating      bool
+		operation     admissionv1beta1.Operation
+		resp          admission.Response
+		wantOperation string
+		wantResult    string
+	}{
+		{
+			name:          "allowed request",
+			kind:          "TestHandlerAllowed",
+			operation:     admissionv1beta1.Create,
+			resp:          admission.Allowed(""),
+			wantOperation: "create",
+			wantResult:    ResultAllowed,
+		},
+		{
+			name:          "denied request",
+			kind:          "TestHandlerDenied",
+			operation:     admissionv1beta1.Update,
+			resp:          admission.Denied("invalid"),
+			wantOperation: "update",
+			wantResult:    ResultDenied,
+		},
+		{
+			name:          "malformed request",
+			kind:          "TestHandlerError",
+			operation:     admissionv1beta1.Update,
+			resp:          admission.Errored(http.StatusBadRequest, errors.New("expected a Widget")),
+			wantOperation: "update",
+			wantResult:    ResultError,
+		},
+		{
+			name:          "defaulting request",
+			kind:          "TestHandlerDefault",
+			mutating:      true,
+			operation:     admissionv1beta1.Create,
+			resp:          admission.Allowed(""),
+			wantOperation: OperationDefault,
+			wantResult:    ResultAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			h := &handler{
+				kind:     tt.kind,
+				mutating: tt.mutating,
+				handler: admission.HandlerFunc(func(context.Context, admission.Request) admission.Response {
+					return tt.resp
+				}),
+			}
+			req := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{Operation: tt.operation}}
+
+			g.Expect(h.Handle(context.Background(), req)).To(Equal(tt.resp))
+			g.Expect(testutil.ToFloat64(RequestsTotal.WithLabelValues(tt.kind, tt.wantOperation, tt.wantResult))).To(Equal(1.0))
+		})
+	}
+}
+
+func TestWebhookPath(t *testing.T) {
+	g := NewWithT(t)
+
+	gvk := schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1alpha3", Kind: "MachineDeployment"}
+	g.Expect(webhookPath("mutate", gvk)).To(Equal("/mutate-cluster-x-k8s-io-v1alpha3-machinedeployment"))
+	g.Expect(webhookPath("validate", gvk)).To(Equal("/validate-cluster-x-k8s-io-v1alpha3-machinedeployment"))
+}
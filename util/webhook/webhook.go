@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains helpers shared by the Cluster API admission webhooks.
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Operations reported in the rejection logs.
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+)
+
+// Results reported in the rejection logs.
+const (
+	// ResultDenied is reported when the request is rejected because the object is invalid or forbidden.
+	ResultDenied = "denied"
+
+	// ResultError is reported when the request is rejected for any other reason, e.g. a malformed request.
+	ResultError = "error"
+)
+
+var (
+	// Log is the logger used to log the admission requests rejected by the validation webhooks.
+	Log = logf.Log.WithName("webhook")
+
+	// RejectionLogVerbosity is the verbosity at which rejected admission requests are logged.
+	RejectionLogVerbosity = 0
+)
+
+// Validate decorates a validation webhook for obj, logging the request if it's rejected.
+// The error returned by validate is returned as is.
+func Validate(gvk schema.GroupVersionKind, operation string, obj runtime.Object, validate func() error) error {
+	err := validate()
+	if err != nil {
+		logRejection(gvk, operation, obj, result(err), err)
+	}
+	return err
+}
+
+func result(err error) string {
+	if apierrors.IsInvalid(err) || apierrors.IsForbidden(err) {
+		return ResultDenied
+	}
+	return ResultError
+}
+
+// logRejection logs a rejected admission request along with the name of the validation webhook, so that it can be
+// told apart from the rejections of other admission plugins.
+func logRejection(gvk schema.GroupVersionKind, operation string, obj runtime.Object, result string, err error) {
+	keysAndValues := []interface{}{
+		"webhook", fmt.Sprintf("validation.%s.%s", strings.ToLower(gvk.Kind), gvk.Group),
+		"operation", operation,
+		"result", result,
+		"gvk", gvk.String(),
+	}
+	if accessor, accessorErr := meta.Accessor(obj); accessorErr == nil {
+		keysAndValues = append(keysAndValues, "namespace", accessor.GetNamespace(), "name", accessor.GetName())
+	}
+	if causes := rejectionCauses(err); len(causes) > 0 {
+		keysAndValues = append(keysAndValues, "causes", causes)
+	}
+	Log.V(RejectionLogVerbosity).Info("Rejected admission request", append(keysAndValues, "error", err.Error())...)
+}
+
+// rejectionCauses returns the field errors of an API status error, formatted as "field: message".
+func rejectionCauses(err error) []string {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
+	causes := make([]string, 0, len(status.Status().Details.Causes))
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Field == "" {
+			causes = append(causes, cause.Message)
+			continue
+		}
+		causes = append(causes, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+	}
+	return causes
+}
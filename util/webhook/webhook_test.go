@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateLogsRejections(t *testing.T) {
+	g := NewWithT(t)
+
+	logger := &recordingLogger{}
+	defer func(l logr.Logger, v int) { Log, RejectionLogVerbosity = l, v }(Log, RejectionLogVerbosity)
+	Log, RejectionLogVerbosity = logger, 2
+
+	gvk := schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1alpha3", Kind: "Widget"}
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"}}
+
+	g.Expect(Validate(gvk, OperationCreate, obj, func() error { return nil })).To(Succeed())
+	g.Expect(logger.lines).To(BeEmpty())
+
+	invalid := apierrors.NewInvalid(gvk.GroupKind(), "foo", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})
+	g.Expect(Validate(gvk, OperationCreate, obj, func() error { return invalid })).NotTo(Succeed())
+	g.Expect(logger.lines).To(HaveLen(1))
+	g.Expect(logger.level).To(Equal(2))
+
+	line := logger.lines[0]
+	g.Expect(line["webhook"]).To(Equal("validation.widget.cluster.x-k8s.io"))
+	g.Expect(line["operation"]).To(Equal(OperationCreate))
+	g.Expect(line["result"]).To(Equal(ResultDenied))
+	g.Expect(line["gvk"]).To(Equal(gvk.String()))
+	g.Expect(line["namespace"]).To(Equal("bar"))
+	g.Expect(line["name"]).To(Equal("foo"))
+	g.Expect(line["causes"]).To(ConsistOf(ContainSubstring("spec.replicas: ")))
+}
+
+type recordingLogger struct {
+	logr.Logger
+	level int
+	lines []map[string]interface{}
+}
+
+func (l *recordingLogger) V(level int) logr.InfoLogger {
+	l.level = level
+	return l
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	data := make(map[string]interface{})
+	for i := 0; i < len(keysAndValues); i += 2 {
+		data[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.lines = append(l.lines, data)
+}